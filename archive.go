@@ -0,0 +1,248 @@
+package response
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+type (
+	// ArchiveEntry describes a single file within an archive.
+	ArchiveEntry struct {
+		// Name is the slash-separated path of the file within the archive.
+		Name string
+
+		// ModTime is the modification time recorded for the file.
+		ModTime time.Time
+
+		// Size is the number of bytes produced by the opened reader. Tar
+		// archives record the size before the content, so entries with a
+		// negative size are read into memory first. Zip archives ignore it.
+		Size int64
+
+		// Open returns the content of the file. It is called only once the
+		// entry is written and the reader is closed afterwards.
+		Open func() (io.ReadCloser, error)
+	}
+
+	// ArchiveIterator returns the next entry of an archive. It returns the
+	// error io.EOF once there are no more entries.
+	ArchiveIterator func() (ArchiveEntry, error)
+
+	// ArchiveProgress describes an entry which has been written completely.
+	ArchiveProgress struct {
+		Index int
+		Name  string
+		Bytes int64
+	}
+
+	archiveConfig struct {
+		progress chan<- ArchiveProgress
+		compress bool
+	}
+
+	// ArchiveConfigFunc is a function used to configure the Zip and Tar
+	// constructors.
+	ArchiveConfigFunc func(*archiveConfig)
+
+	// archiveWriter abstracts the differences between zip and tar writers.
+	archiveWriter interface {
+		create(entry ArchiveEntry) (io.Writer, error)
+		Close() error
+	}
+
+	zipWriter struct {
+		*zip.Writer
+	}
+
+	tarWriter struct {
+		*tar.Writer
+	}
+)
+
+// ArchiveEntries creates an iterator over the given entries.
+func ArchiveEntries(entries ...ArchiveEntry) ArchiveIterator {
+	return func() (ArchiveEntry, error) {
+		if len(entries) == 0 {
+			return ArchiveEntry{}, io.EOF
+		}
+
+		entry := entries[0]
+		entries = entries[1:]
+		return entry, nil
+	}
+}
+
+// WithEntryProgressChan instructs Zip and Tar to send a value to this chan
+// after each entry is written. Consumers of this channel should be efficient
+// as this write will block progress. This channel is closed by the WriteTo
+// method.
+func WithEntryProgressChan(progress chan<- ArchiveProgress) ArchiveConfigFunc {
+	return func(c *archiveConfig) { c.progress = progress }
+}
+
+// WithGzip instructs Tar to compress the archive with gzip.
+func WithGzip() ArchiveConfigFunc {
+	return func(c *archiveConfig) { c.compress = true }
+}
+
+// Zip creates a response which streams a zip archive containing the entries
+// of the given iterator. The archive is written directly to the client
+// without temporary files. Writing stops early if the client disconnects.
+func Zip(filename string, next ArchiveIterator, configs ...ArchiveConfigFunc) Response {
+	config := newArchiveConfig(configs)
+
+	resp := newResponse(func(w io.Writer) error {
+		return writeArchive(w, &zipWriter{zip.NewWriter(w)}, next, config)
+	})
+
+	resp.release = config.release
+	resp.SetHeader("Content-Type", "application/zip")
+	return Attachment(resp, filename)
+}
+
+// Tar creates a response which streams a tar archive containing the entries
+// of the given iterator. The archive is written directly to the client
+// without temporary files. Writing stops early if the client disconnects.
+func Tar(filename string, next ArchiveIterator, configs ...ArchiveConfigFunc) Response {
+	config := newArchiveConfig(configs)
+
+	resp := newResponse(func(w io.Writer) error {
+		if !config.compress {
+			return writeArchive(w, &tarWriter{tar.NewWriter(w)}, next, config)
+		}
+
+		gw := gzip.NewWriter(w)
+		return tryClose(gw, writeArchive(delegateCloseNotify(gw, w), &tarWriter{tar.NewWriter(gw)}, next, config))
+	})
+
+	contentType := "application/x-tar"
+	if config.compress {
+		contentType = "application/gzip"
+	}
+
+	resp.release = config.release
+	resp.SetHeader("Content-Type", contentType)
+	return Attachment(resp, filename)
+}
+
+// newArchiveConfig applies the given config functions over defaults.
+func newArchiveConfig(configs []ArchiveConfigFunc) *archiveConfig {
+	config := &archiveConfig{}
+	for _, f := range configs {
+		f(config)
+	}
+
+	return config
+}
+
+// release closes the progress channel of an archive which is never written.
+func (c *archiveConfig) release() {
+	if c.progress != nil {
+		close(c.progress)
+	}
+}
+
+// writeArchive writes each entry of the iterator to the archive writer. The
+// archive is finalized only if all entries are written successfully and the
+// client is still connected.
+func writeArchive(w io.Writer, aw archiveWriter, next ArchiveIterator, config *archiveConfig) error {
+	if config.progress != nil {
+		defer close(config.progress)
+	}
+
+	buffer := make([]byte, 32*1024)
+
+	for index := 0; !isClosed(w); index++ {
+		entry, err := next()
+		if err != nil {
+			if err == io.EOF {
+				return aw.Close()
+			}
+
+			return err
+		}
+
+		entry.Name = strings.TrimPrefix(path.Clean("/"+entry.Name), "/")
+
+		n, err := writeEntry(w, aw, entry, buffer)
+		if err != nil {
+			return err
+		}
+
+		if config.progress != nil {
+			config.progress <- ArchiveProgress{Index: index, Name: entry.Name, Bytes: n}
+		}
+	}
+
+	return nil
+}
+
+// writeEntry copies the content of a single entry into the archive. The
+// number of bytes copied is returned.
+func writeEntry(w io.Writer, aw archiveWriter, entry ArchiveEntry, buffer []byte) (int64, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+
+	var r io.Reader = rc
+	if _, ok := aw.(*tarWriter); ok && entry.Size < 0 {
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return 0, err
+		}
+
+		r, entry.Size = bytes.NewReader(data), int64(len(data))
+	}
+
+	ew, err := aw.create(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	total := int64(0)
+	for !isClosed(w) {
+		n, err := moveChunk(r, ew, buffer)
+		total += int64(n)
+
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// create adds a file header to the zip archive.
+func (w *zipWriter) create(entry ArchiveEntry) (io.Writer, error) {
+	return w.CreateHeader(&zip.FileHeader{
+		Name:     entry.Name,
+		Modified: entry.ModTime,
+		Method:   zip.Deflate,
+	})
+}
+
+// create adds a file header to the tar archive.
+func (w *tarWriter) create(entry ArchiveEntry) (io.Writer, error) {
+	err := w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     entry.Name,
+		ModTime:  entry.ModTime,
+		Size:     entry.Size,
+		Mode:     0644,
+	})
+
+	return w.Writer, err
+}
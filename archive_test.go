@@ -0,0 +1,187 @@
+package response
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type ArchiveSuite struct{}
+
+func (s *ArchiveSuite) TestZip(t *testing.T) {
+	resp := Zip("bundle.zip", ArchiveEntries(testArchiveEntries(-1)...))
+
+	headers, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Type")).To(Equal("application/zip"))
+	Expect(headers.Get("Content-Disposition")).To(Equal(`attachment; filename="bundle.zip"`))
+
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	Expect(err).To(BeNil())
+	Expect(reader.File).To(HaveLen(3))
+
+	for i, expected := range []string{"a.txt", "dir/b.txt", "etc/passwd"} {
+		Expect(reader.File[i].Name).To(Equal(expected))
+		Expect(reader.File[i].Modified.Unix()).To(Equal(testArchiveTime.Unix()))
+
+		rc, err := reader.File[i].Open()
+		Expect(err).To(BeNil())
+		content, _ := ioutil.ReadAll(rc)
+		Expect(string(content)).To(Equal(fmt.Sprintf("content %d", i)))
+	}
+}
+
+func (s *ArchiveSuite) TestTar(t *testing.T) {
+	for _, size := range []int64{9, -1} {
+		headers, body, err := Serialize(Tar("bundle.tar", ArchiveEntries(testArchiveEntries(size)...)))
+		Expect(err).To(BeNil())
+		Expect(headers.Get("Content-Type")).To(Equal("application/x-tar"))
+		Expect(readTar(bytes.NewReader(body))).To(Equal(map[string]string{
+			"a.txt":      "content 0",
+			"dir/b.txt":  "content 1",
+			"etc/passwd": "content 2",
+		}))
+	}
+}
+
+func (s *ArchiveSuite) TestTarGzip(t *testing.T) {
+	headers, body, err := Serialize(Tar("bundle.tar.gz", ArchiveEntries(testArchiveEntries(9)...), WithGzip()))
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Type")).To(Equal("application/gzip"))
+
+	gr, err := gzip.NewReader(bytes.NewReader(body))
+	Expect(err).To(BeNil())
+	Expect(readTar(gr)).To(HaveLen(3))
+}
+
+func (s *ArchiveSuite) TestTarSizeMismatch(t *testing.T) {
+	_, _, err := Serialize(Tar("bundle.tar", ArchiveEntries(testArchiveEntries(4)...)))
+	Expect(err).NotTo(BeNil())
+}
+
+func (s *ArchiveSuite) TestProgress(t *testing.T) {
+	progress := make(chan ArchiveProgress, 10)
+
+	_, _, err := Serialize(Zip("bundle.zip", ArchiveEntries(testArchiveEntries(-1)...), WithEntryProgressChan(progress)))
+	Expect(err).To(BeNil())
+	Expect(progress).To(Receive(Equal(ArchiveProgress{Index: 0, Name: "a.txt", Bytes: 9})))
+	Expect(progress).To(Receive(Equal(ArchiveProgress{Index: 1, Name: "dir/b.txt", Bytes: 9})))
+	Expect(progress).To(Receive(Equal(ArchiveProgress{Index: 2, Name: "etc/passwd", Bytes: 9})))
+	Expect(progress).To(BeClosed())
+}
+
+func (s *ArchiveSuite) TestOpenError(t *testing.T) {
+	entries := []ArchiveEntry{{
+		Name: "broken",
+		Open: func() (io.ReadCloser, error) { return nil, fmt.Errorf("utoh") },
+	}}
+
+	_, _, err := Serialize(Zip("bundle.zip", ArchiveEntries(entries...)))
+	Expect(err).To(MatchError("utoh"))
+
+	next := func() (ArchiveEntry, error) { return ArchiveEntry{}, fmt.Errorf("iterator failed") }
+	_, _, err = Serialize(Tar("bundle.tar", next))
+	Expect(err).To(MatchError("iterator failed"))
+}
+
+func (s *ArchiveSuite) TestDisconnect(t *testing.T) {
+	var (
+		opened    = 0
+		closeChan = make(chan bool)
+		progress  = make(chan ArchiveProgress)
+		writer    = &decoratedRecorder{httptest.NewRecorder(), closeChan, nil}
+	)
+
+	next := func() (ArchiveEntry, error) {
+		return ArchiveEntry{
+			Name: "infinite",
+			Size: -1,
+			Open: func() (io.ReadCloser, error) {
+				opened++
+				return &closer{bytes.NewReader(makeData()), false}, nil
+			},
+		}, nil
+	}
+
+	go func() {
+		<-progress
+		<-progress
+		close(closeChan)
+
+		for range progress {
+		}
+	}()
+
+	Zip("bundle.zip", next, WithEntryProgressChan(progress)).WriteTo(writer)
+	Expect(opened).To(BeNumerically("<=", 3))
+}
+
+//
+//
+
+var testArchiveTime = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testArchiveEntries(size int64) []ArchiveEntry {
+	entries := []ArchiveEntry{}
+	for i, name := range []string{"a.txt", "/dir/b.txt", "../../etc/passwd"} {
+		content := fmt.Sprintf("content %d", i)
+
+		entries = append(entries, ArchiveEntry{
+			Name:    name,
+			ModTime: testArchiveTime,
+			Size:    size,
+			Open: func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader([]byte(content))), nil
+			},
+		})
+	}
+
+	return entries
+}
+
+func readTar(r io.Reader) map[string]string {
+	files := map[string]string{}
+	reader := tar.NewReader(r)
+
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		Expect(err).To(BeNil())
+		Expect(header.ModTime.Unix()).To(Equal(testArchiveTime.Unix()))
+		content, _ := ioutil.ReadAll(reader)
+		files[header.Name] = string(content)
+	}
+
+	return files
+}
+
+func (s *ArchiveSuite) TestHead(t *testing.T) {
+	progress := make(chan ArchiveProgress)
+
+	handler := Convert(func(r *http.Request) Response {
+		return Zip("bundle.zip", func() (ArchiveEntry, error) {
+			panic("iterator should not be called")
+		}, WithEntryProgressChan(progress))
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("HEAD", "/", nil))
+	Expect(w.Header().Get("Content-Type")).To(Equal("application/zip"))
+	Expect(w.Body.Len()).To(Equal(0))
+
+	_, ok := <-progress
+	Expect(ok).To(BeFalse())
+}
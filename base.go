@@ -2,17 +2,26 @@ package response
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"net/http"
 )
 
-// Respond creates a response with the given body.
-func Respond(data []byte) Response {
-	writer := func(w io.Writer) error {
-		return writeAll(w, data)
+type (
+	xmlConfig struct {
+		header bool
+		prefix string
+		indent string
 	}
 
-	resp := newResponse(writer)
+	// XMLConfigFunc is a function used to configure the XML constructor.
+	XMLConfigFunc func(*xmlConfig)
+)
+
+// Respond creates a response with the given body.
+func Respond(data []byte) Response {
+	resp := newBufferedResponse(data)
 	resp.SetHeader("Content-Length", fmt.Sprintf("%d", len(data)))
 	return resp
 }
@@ -26,11 +35,82 @@ func Empty(statusCode int) Response {
 }
 
 // JSON creates a response with the data serialized as JSON for the body.
+// If the data cannot be serialized, the response has status 500 and the
+// serialization error is reported to callbacks.
 func JSON(data interface{}) Response {
-	body, _ := json.Marshal(data)
+	body, err := json.Marshal(data)
+	if err != nil {
+		return failure(err)
+	}
 
 	resp := Respond(body)
 	resp.SetHeader("Content-Type", "application/json")
 	resp.SetHeader("Content-Length", fmt.Sprintf("%d", len(body)))
 	return resp
 }
+
+// XML creates a response with the data serialized as XML for the body.
+// If the data cannot be serialized, the response has status 500 and the
+// serialization error is reported to callbacks.
+func XML(data interface{}, configs ...XMLConfigFunc) Response {
+	config := &xmlConfig{}
+	for _, f := range configs {
+		f(config)
+	}
+
+	body, err := xml.MarshalIndent(data, config.prefix, config.indent)
+	if err != nil {
+		return failure(err)
+	}
+
+	if config.header {
+		body = append([]byte(xml.Header), body...)
+	}
+
+	resp := Respond(body)
+	resp.SetHeader("Content-Type", "application/xml; charset=utf-8")
+	return resp
+}
+
+// WithXMLHeader instructs XML to prepend the standard XML declaration
+// (xml.Header) to the body.
+func WithXMLHeader() XMLConfigFunc {
+	return func(c *xmlConfig) { c.header = true }
+}
+
+// WithXMLIndent instructs XML to indent nested elements (see the function
+// xml.MarshalIndent).
+func WithXMLIndent(prefix, indent string) XMLConfigFunc {
+	return func(c *xmlConfig) { c.prefix, c.indent = prefix, indent }
+}
+
+// Text creates a response with a plain text body. The body is formatted
+// with fmt.Sprintf when arguments are supplied.
+func Text(format string, args ...interface{}) Response {
+	if len(args) > 0 {
+		format = fmt.Sprintf(format, args...)
+	}
+
+	resp := Respond([]byte(format))
+	resp.SetHeader("Content-Type", "text/plain; charset=utf-8")
+	return resp
+}
+
+// HTML creates a response with the given HTML document as the body. The
+// content is sent as-is and must already be escaped.
+func HTML(content string) Response {
+	resp := Respond([]byte(content))
+	resp.SetHeader("Content-Type", "text/html; charset=utf-8")
+	return resp
+}
+
+// failure creates a response with status 500 and an empty body which
+// reports the given error to callbacks once written (including when only
+// the headers are written in response to a HEAD request).
+func failure(err error) Response {
+	resp := newResponse(func(io.Writer) error { return err })
+	resp.err = err
+	resp.SetStatusCode(http.StatusInternalServerError)
+	resp.SetHeader("Content-Length", "0")
+	return resp
+}
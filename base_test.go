@@ -1,15 +1,16 @@
 package response
 
 import (
+	"encoding/xml"
 	"net/http"
+	"testing"
 
-	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
 )
 
 type BaseSuite struct{}
 
-func (s *BaseSuite) TestRespond(t sweet.T) {
+func (s *BaseSuite) TestRespond(t *testing.T) {
 	var (
 		data1 = []byte{1, 3, 5, 7, 9}
 		data2 = []byte{2, 4, 6, 8, 0, 12}
@@ -30,7 +31,7 @@ func (s *BaseSuite) TestRespond(t sweet.T) {
 	Expect(headers.Get("Content-Length")).To(Equal("6"))
 }
 
-func (s *BaseSuite) TestEmpty(t sweet.T) {
+func (s *BaseSuite) TestEmpty(t *testing.T) {
 	r1 := Empty(http.StatusNotFound)
 	Expect(r1.StatusCode()).To(Equal(http.StatusNotFound))
 	headers, body, _ := Serialize(r1)
@@ -44,7 +45,7 @@ func (s *BaseSuite) TestEmpty(t sweet.T) {
 	Expect(headers.Get("Content-Length")).To(Equal("0"))
 }
 
-func (s *BaseSuite) TestJSON(t sweet.T) {
+func (s *BaseSuite) TestJSON(t *testing.T) {
 	payload := SampleJSON{
 		PropertyA: "foo",
 		PropertyB: "bar",
@@ -59,8 +60,77 @@ func (s *BaseSuite) TestJSON(t sweet.T) {
 	Expect(headers.Get("Content-Length")).To(Equal("46"))
 }
 
+func (s *BaseSuite) TestJSONError(t *testing.T) {
+	r := JSON(map[string]interface{}{"foo": make(chan int)})
+	Expect(r.StatusCode()).To(Equal(http.StatusInternalServerError))
+	headers, body, err := Serialize(r)
+	Expect(err).NotTo(BeNil())
+	Expect(body).To(BeEmpty())
+	Expect(headers.Get("Content-Length")).To(Equal("0"))
+}
+
+func (s *BaseSuite) TestXML(t *testing.T) {
+	payload := SampleXML{
+		PropertyA: "foo",
+		PropertyB: "bar",
+	}
+
+	r := XML(payload)
+	Expect(r.Header("Content-Type")).To(Equal("application/xml; charset=utf-8"))
+	headers, body, err := Serialize(r)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal(`<sample id="foo"><prop_b>bar</prop_b></sample>`))
+	Expect(headers.Get("Content-Length")).To(Equal("46"))
+}
+
+func (s *BaseSuite) TestXMLHeaderIndent(t *testing.T) {
+	payload := SampleXML{
+		PropertyA: "foo",
+		PropertyB: "bar",
+	}
+
+	_, body, err := Serialize(XML(payload, WithXMLHeader(), WithXMLIndent("", "  ")))
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal(xml.Header + "<sample id=\"foo\">\n  <prop_b>bar</prop_b>\n</sample>"))
+}
+
+func (s *BaseSuite) TestXMLError(t *testing.T) {
+	r := XML(map[string]string{"foo": "bar"})
+	Expect(r.StatusCode()).To(Equal(http.StatusInternalServerError))
+	_, body, err := Serialize(r)
+	Expect(err).NotTo(BeNil())
+	Expect(body).To(BeEmpty())
+}
+
+func (s *BaseSuite) TestText(t *testing.T) {
+	r := Text("%d%% of %s", 50, "quota")
+	Expect(r.Header("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+	headers, body, err := Serialize(r)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("50% of quota"))
+	Expect(headers.Get("Content-Length")).To(Equal("12"))
+
+	_, body, _ = Serialize(Text("100%"))
+	Expect(string(body)).To(Equal("100%"))
+}
+
+func (s *BaseSuite) TestHTML(t *testing.T) {
+	r := HTML("<p>caf\u00e9</p>")
+	Expect(r.Header("Content-Type")).To(Equal("text/html; charset=utf-8"))
+	headers, body, err := Serialize(r)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("<p>caf\u00e9</p>"))
+	Expect(headers.Get("Content-Length")).To(Equal("12"))
+}
+
 type SampleJSON struct {
 	PropertyA string `json:"prop_a"`
 	PropertyB string `json:"prop_b"`
 	PropertyC string `json:"prop_c"`
 }
+
+type SampleXML struct {
+	XMLName   xml.Name `xml:"sample"`
+	PropertyA string   `xml:"id,attr"`
+	PropertyB string   `xml:"prop_b"`
+}
@@ -0,0 +1,600 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	// Contract checks responses against the operations described by an
+	// OpenAPI document.
+	Contract struct {
+		document map[string]interface{}
+		routes   *routeTable
+		paths    map[*route]string
+	}
+
+	// Violation describes a way in which a response does not conform to
+	// the contract. The pointer is a JSON pointer to the offending value in
+	// the response body and is empty for violations which do not concern the
+	// body. The schema pointer is a JSON pointer to the part of the document
+	// which the response violates.
+	Violation struct {
+		Method        string
+		Path          string
+		StatusCode    int
+		Pointer       string
+		SchemaPointer string
+		Message       string
+	}
+
+	// ViolationHandler is invoked with the violations of a response.
+	ViolationHandler func(r *http.Request, violations []Violation)
+
+	// schemaValidator validates JSON values against the schemas of a
+	// document and accumulates the violations it finds.
+	schemaValidator struct {
+		document   map[string]interface{}
+		violations []schemaViolation
+	}
+
+	schemaViolation struct {
+		pointer       string
+		schemaPointer string
+		message       string
+	}
+)
+
+// NewContract creates a contract from the given OpenAPI document (in JSON
+// format, such as the output of OpenAPI).
+func NewContract(document []byte) (*Contract, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	contract := &Contract{
+		routes: &routeTable{},
+		paths:  map[*route]string{},
+	}
+
+	if err := decoder.Decode(&contract.document); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	paths, _ := contract.document["paths"].(map[string]interface{})
+	for path := range paths {
+		segments, err := parsePattern(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+
+		rt := &route{segments: segments}
+		contract.routes.routes = append(contract.routes.routes, rt)
+		contract.paths[rt] = path
+	}
+
+	return contract, nil
+}
+
+// VerifyContract creates middleware which checks every response against
+// the contract. The response is materialized with Serialize in order to
+// inspect its body, and an equivalent response is returned in its place.
+// The handler is invoked for each response which violates the contract.
+func VerifyContract(contract *Contract, handler ViolationHandler) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(r *http.Request) Response {
+			resp, violations := contract.Check(r, next(r))
+			if len(violations) > 0 {
+				handler(r, violations)
+			}
+
+			return resp
+		}
+	}
+}
+
+// Check verifies that the response to the given request is declared by
+// the contract: the path, method, and status code must be documented,
+// the required headers must be present, the content type must be allowed,
+// and a JSON body must conform to its schema. The response is consumed,
+// so an equivalent response is returned along with the violations.
+func (c *Contract) Check(r *http.Request, resp Response) (Response, []Violation) {
+	statusCode := resp.StatusCode()
+	header, body, err := Serialize(resp)
+	resp = Reconstruct(statusCode, header, body)
+
+	violations := []Violation{}
+	report := func(pointer, schemaPointer, format string, args ...interface{}) {
+		violations = append(violations, Violation{
+			Method:        r.Method,
+			Path:          r.URL.Path,
+			StatusCode:    statusCode,
+			Pointer:       pointer,
+			SchemaPointer: schemaPointer,
+			Message:       fmt.Sprintf(format, args...),
+		})
+	}
+
+	if err != nil {
+		report("", "", "failed to write response: %s", err)
+	}
+
+	rt, _ := c.routes.match(r.URL)
+	if rt == nil {
+		report("", "/paths", "path is not declared")
+		return resp, violations
+	}
+
+	path := c.paths[rt]
+	pathPointer := "/paths/" + escapePointer(path)
+
+	method := strings.ToLower(r.Method)
+	operation, ok := lookup(c.document, "paths", path, method).(map[string]interface{})
+	if !ok && method == "head" {
+		method = "get"
+		operation, ok = lookup(c.document, "paths", path, method).(map[string]interface{})
+	}
+
+	if !ok {
+		report("", pathPointer, "method is not declared")
+		return resp, violations
+	}
+
+	responses, _ := operation["responses"].(map[string]interface{})
+	key, ok := responseKey(responses, statusCode)
+	if !ok {
+		report("", pathPointer+"/"+method+"/responses", "status code is not declared")
+		return resp, violations
+	}
+
+	declared, _ := c.resolve(responses[key]).(map[string]interface{})
+	responsePointer := pathPointer + "/" + method + "/responses/" + escapePointer(key)
+
+	headers, _ := declared["headers"].(map[string]interface{})
+	for _, name := range sortedKeys(headers) {
+		definition, _ := c.resolve(headers[name]).(map[string]interface{})
+		if required, _ := definition["required"].(bool); required && header.Get(name) == "" {
+			report("", responsePointer+"/headers/"+escapePointer(name), "required header %s is missing", name)
+		}
+	}
+
+	if r.Method == http.MethodHead {
+		return resp, violations
+	}
+
+	content, _ := declared["content"].(map[string]interface{})
+	if len(content) == 0 {
+		if len(body) > 0 {
+			report("", responsePointer, "response body is not declared")
+		}
+
+		return resp, violations
+	}
+
+	contentType := header.Get("Content-Type")
+	mediaType, ok := matchMediaType(content, contentType)
+	if !ok {
+		report("", responsePointer+"/content", "content type %q is not declared", contentType)
+		return resp, violations
+	}
+
+	mediaPointer := responsePointer + "/content/" + escapePointer(mediaType)
+	definition, _ := content[mediaType].(map[string]interface{})
+	schema, ok := definition["schema"]
+	if !ok || !isJSONMediaType(contentType) {
+		return resp, violations
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		report("", mediaPointer, "response body is not valid JSON: %s", err)
+		return resp, violations
+	}
+
+	validator := &schemaValidator{document: c.document}
+	validator.validate(value, schema, "", mediaPointer+"/schema")
+
+	for _, v := range validator.violations {
+		report(v.pointer, v.schemaPointer, "%s", v.message)
+	}
+
+	return resp, violations
+}
+
+// String returns a description of the violation.
+func (v Violation) String() string {
+	location := ""
+	if v.Pointer != "" {
+		location = fmt.Sprintf(" at %s", v.Pointer)
+	}
+
+	return fmt.Sprintf("%s %s (%d)%s: %s (see %s)", v.Method, v.Path, v.StatusCode, location, v.Message, v.SchemaPointer)
+}
+
+// resolve follows a local reference.
+func (c *Contract) resolve(value interface{}) interface{} {
+	value, _ = resolveRef(c.document, value, "")
+	return value
+}
+
+// responseKey returns the key of the responses object which describes the
+// given status code: the exact code, its range (such as 4XX), or default.
+func responseKey(responses map[string]interface{}, statusCode int) (string, bool) {
+	for _, key := range []string{strconv.Itoa(statusCode), fmt.Sprintf("%dXX", statusCode/100), "default"} {
+		if _, ok := responses[key]; ok {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// matchMediaType returns the key of the content object which describes
+// the given content type. Keys may contain wildcards (text/* or */*).
+func matchMediaType(content map[string]interface{}, contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	if _, ok := content[mediaType]; ok {
+		return mediaType, true
+	}
+
+	if i := strings.Index(mediaType, "/"); i >= 0 {
+		if key := mediaType[:i] + "/*"; content[key] != nil {
+			return key, true
+		}
+	}
+
+	if content["*/*"] != nil {
+		return "*/*", true
+	}
+
+	return "", false
+}
+
+// validate checks the value against the schema. The pointer is the location
+// of the value and the schema pointer is the location of the schema.
+func (v *schemaValidator) validate(value, schema interface{}, pointer, schemaPointer string) {
+	schema, schemaPointer = resolveRef(v.document, schema, schemaPointer)
+
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		if b, ok := schema.(bool); ok && !b {
+			v.report(pointer, schemaPointer, "value is not allowed")
+		}
+
+		return
+	}
+
+	if t, ok := s["type"]; ok && !v.checkType(value, t, s, pointer, schemaPointer) {
+		return
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonEqual(value, candidate) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			v.report(pointer, schemaPointer+"/enum", "value is not one of the allowed values")
+		}
+	}
+
+	if constant, ok := s["const"]; ok && !jsonEqual(value, constant) {
+		v.report(pointer, schemaPointer+"/const", "value does not equal the constant value")
+	}
+
+	v.validateComposition(value, s, pointer, schemaPointer)
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(val, s, pointer, schemaPointer)
+	case []interface{}:
+		v.validateArray(val, s, pointer, schemaPointer)
+	case string:
+		v.validateString(val, s, pointer, schemaPointer)
+	case json.Number:
+		v.validateNumber(val, s, pointer, schemaPointer)
+	}
+}
+
+// checkType returns true if the value has (one of) the given type(s). The
+// OpenAPI 3.0 nullable keyword is also honored.
+func (v *schemaValidator) checkType(value, t interface{}, s map[string]interface{}, pointer, schemaPointer string) bool {
+	types := []string{}
+	switch t := t.(type) {
+	case string:
+		types = append(types, t)
+	case []interface{}:
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+
+	if nullable, _ := s["nullable"].(bool); nullable {
+		types = append(types, "null")
+	}
+
+	actual := jsonType(value)
+	for _, name := range types {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	v.report(pointer, schemaPointer+"/type", "expected %s, got %s", strings.Join(types, " or "), actual)
+	return false
+}
+
+// validateComposition checks the allOf, anyOf, oneOf, and not keywords.
+func (v *schemaValidator) validateComposition(value interface{}, s map[string]interface{}, pointer, schemaPointer string) {
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for i, sub := range allOf {
+			v.validate(value, sub, pointer, fmt.Sprintf("%s/allOf/%d", schemaPointer, i))
+		}
+	}
+
+	if anyOf, ok := s["anyOf"].([]interface{}); ok && v.countMatches(value, anyOf, pointer, schemaPointer+"/anyOf") == 0 {
+		v.report(pointer, schemaPointer+"/anyOf", "value does not match any schema")
+	}
+
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		if n := v.countMatches(value, oneOf, pointer, schemaPointer+"/oneOf"); n != 1 {
+			v.report(pointer, schemaPointer+"/oneOf", "value matches %d schemas instead of exactly one", n)
+		}
+	}
+
+	if not, ok := s["not"]; ok && v.matches(value, not, pointer, schemaPointer+"/not") {
+		v.report(pointer, schemaPointer+"/not", "value matches a disallowed schema")
+	}
+}
+
+// validateObject checks the object keywords of the schema.
+func (v *schemaValidator) validateObject(value map[string]interface{}, s map[string]interface{}, pointer, schemaPointer string) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := value[name]; !ok {
+					v.report(pointer, schemaPointer+"/required", "required property %q is missing", name)
+				}
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	for _, name := range sortedKeys(value) {
+		if schema, ok := properties[name]; ok {
+			v.validate(value[name], schema, pointer+"/"+escapePointer(name), schemaPointer+"/properties/"+escapePointer(name))
+		} else if additional, ok := s["additionalProperties"]; ok {
+			v.validate(value[name], additional, pointer+"/"+escapePointer(name), schemaPointer+"/additionalProperties")
+		}
+	}
+
+	if n, ok := schemaInt(s, "minProperties"); ok && len(value) < n {
+		v.report(pointer, schemaPointer+"/minProperties", "expected at least %d properties", n)
+	}
+
+	if n, ok := schemaInt(s, "maxProperties"); ok && len(value) > n {
+		v.report(pointer, schemaPointer+"/maxProperties", "expected at most %d properties", n)
+	}
+}
+
+// validateArray checks the array keywords of the schema.
+func (v *schemaValidator) validateArray(value []interface{}, s map[string]interface{}, pointer, schemaPointer string) {
+	if items, ok := s["items"]; ok {
+		for i, item := range value {
+			v.validate(item, items, fmt.Sprintf("%s/%d", pointer, i), schemaPointer+"/items")
+		}
+	}
+
+	if n, ok := schemaInt(s, "minItems"); ok && len(value) < n {
+		v.report(pointer, schemaPointer+"/minItems", "expected at least %d items", n)
+	}
+
+	if n, ok := schemaInt(s, "maxItems"); ok && len(value) > n {
+		v.report(pointer, schemaPointer+"/maxItems", "expected at most %d items", n)
+	}
+}
+
+// validateString checks the string keywords of the schema.
+func (v *schemaValidator) validateString(value string, s map[string]interface{}, pointer, schemaPointer string) {
+	length := utf8.RuneCountInString(value)
+
+	if n, ok := schemaInt(s, "minLength"); ok && length < n {
+		v.report(pointer, schemaPointer+"/minLength", "expected at least %d characters", n)
+	}
+
+	if n, ok := schemaInt(s, "maxLength"); ok && length > n {
+		v.report(pointer, schemaPointer+"/maxLength", "expected at most %d characters", n)
+	}
+
+	if pattern, ok := s["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(value) {
+			v.report(pointer, schemaPointer+"/pattern", "value does not match pattern %s", pattern)
+		}
+	}
+}
+
+// validateNumber checks the numeric keywords of the schema.
+func (v *schemaValidator) validateNumber(value json.Number, s map[string]interface{}, pointer, schemaPointer string) {
+	f, err := value.Float64()
+	if err != nil {
+		return
+	}
+
+	if min, ok := schemaFloat(s, "minimum"); ok && f < min {
+		v.report(pointer, schemaPointer+"/minimum", "expected a value of at least %v", min)
+	}
+
+	if max, ok := schemaFloat(s, "maximum"); ok && f > max {
+		v.report(pointer, schemaPointer+"/maximum", "expected a value of at most %v", max)
+	}
+
+	if min, ok := schemaFloat(s, "exclusiveMinimum"); ok && f <= min {
+		v.report(pointer, schemaPointer+"/exclusiveMinimum", "expected a value greater than %v", min)
+	}
+
+	if max, ok := schemaFloat(s, "exclusiveMaximum"); ok && f >= max {
+		v.report(pointer, schemaPointer+"/exclusiveMaximum", "expected a value less than %v", max)
+	}
+}
+
+// countMatches returns the number of schemas which the value matches.
+func (v *schemaValidator) countMatches(value interface{}, schemas []interface{}, pointer, schemaPointer string) int {
+	n := 0
+	for i, schema := range schemas {
+		if v.matches(value, schema, pointer, fmt.Sprintf("%s/%d", schemaPointer, i)) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// matches returns true if the value conforms to the schema. Violations found
+// while checking are not reported.
+func (v *schemaValidator) matches(value, schema interface{}, pointer, schemaPointer string) bool {
+	sub := &schemaValidator{document: v.document}
+	sub.validate(value, schema, pointer, schemaPointer)
+	return len(sub.violations) == 0
+}
+
+// report records a violation.
+func (v *schemaValidator) report(pointer, schemaPointer, format string, args ...interface{}) {
+	v.violations = append(v.violations, schemaViolation{pointer, schemaPointer, fmt.Sprintf(format, args...)})
+}
+
+// resolveRef follows local references ($ref values beginning with #) until
+// a schema without a reference is reached. The pointer of the resolved value
+// is returned along with the value.
+func resolveRef(document map[string]interface{}, value interface{}, pointer string) (interface{}, string) {
+	for i := 0; i < 32; i++ {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			break
+		}
+
+		pointer = strings.TrimPrefix(ref, "#")
+
+		tokens := []string{}
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			tokens = append(tokens, strings.NewReplacer("~1", "/", "~0", "~").Replace(token))
+		}
+
+		value = lookup(document, tokens...)
+	}
+
+	return value, pointer
+}
+
+// lookup returns the value at the given path of object keys, or nil.
+func lookup(document map[string]interface{}, keys ...string) interface{} {
+	var value interface{} = document
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		value = m[key]
+	}
+
+	return value
+}
+
+// escapePointer escapes a reference token of a JSON pointer.
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// jsonType returns the name of the JSON Schema type of the value.
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if f, err := value.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+
+		return "number"
+	}
+
+	return "unknown"
+}
+
+// jsonEqual returns true if both decoded JSON values are equal. Numbers are
+// compared by value.
+func jsonEqual(a, b interface{}) bool {
+	if na, ok := a.(json.Number); ok {
+		if nb, ok := b.(json.Number); ok {
+			fa, errA := na.Float64()
+			fb, errB := nb.Float64()
+			return errA == nil && errB == nil && fa == fb
+		}
+	}
+
+	ea, errA := json.Marshal(a)
+	eb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ea, eb)
+}
+
+// schemaFloat returns the numeric value of the schema keyword.
+func schemaFloat(s map[string]interface{}, keyword string) (float64, bool) {
+	if n, ok := s[keyword].(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return f, true
+		}
+	}
+
+	return 0, false
+}
+
+// schemaInt returns the integer value of the schema keyword.
+func schemaInt(s map[string]interface{}, keyword string) (int, bool) {
+	f, ok := schemaFloat(s, keyword)
+	return int(f), ok
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
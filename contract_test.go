@@ -0,0 +1,225 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type ContractSuite struct{}
+
+func (s *ContractSuite) TestConformingResponse(t *testing.T) {
+	contract := widgetContract()
+
+	resp, violations := contract.Check(httptest.NewRequest("GET", "/api/widgets/7", nil), JSON(openAPIWidget{ID: "7", Name: "gear"}))
+	Expect(violations).To(BeEmpty())
+
+	_, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(body).To(MatchJSON(`{"id": "7", "name": "gear"}`))
+}
+
+func (s *ContractSuite) TestBodyViolations(t *testing.T) {
+	contract := widgetContract()
+
+	_, violations := contract.Check(httptest.NewRequest("GET", "/api/widgets/7", nil), JSON(map[string]interface{}{"id": 7}))
+	Expect(violations).To(ConsistOf(
+		Violation{
+			Method:        "GET",
+			Path:          "/api/widgets/7",
+			StatusCode:    200,
+			Pointer:       "",
+			SchemaPointer: "/components/schemas/openAPIWidget/required",
+			Message:       `required property "name" is missing`,
+		},
+		Violation{
+			Method:        "GET",
+			Path:          "/api/widgets/7",
+			StatusCode:    200,
+			Pointer:       "/id",
+			SchemaPointer: "/components/schemas/openAPIWidget/properties/id/type",
+			Message:       "expected string, got integer",
+		},
+	))
+
+	Expect(violations[0].String()).To(HavePrefix("GET /api/widgets/7 (200)"))
+}
+
+func (s *ContractSuite) TestStatusCode(t *testing.T) {
+	_, violations := widgetContract().Check(httptest.NewRequest("GET", "/api/widgets/7", nil), Empty(http.StatusTeapot))
+	Expect(violations).To(HaveLen(1))
+	Expect(violations[0].Message).To(Equal("status code is not declared"))
+	Expect(violations[0].SchemaPointer).To(Equal("/paths/~1api~1widgets~1{id}/get/responses"))
+}
+
+func (s *ContractSuite) TestRequiredHeaders(t *testing.T) {
+	contract := widgetContract()
+	r := httptest.NewRequest("POST", "/api/widgets", nil)
+
+	_, violations := contract.Check(r, JSON(openAPIWidget{ID: "1", Name: "gear"}).SetStatusCode(http.StatusCreated))
+	Expect(violations).To(HaveLen(1))
+	Expect(violations[0].Message).To(Equal("required header Location is missing"))
+	Expect(violations[0].SchemaPointer).To(Equal("/paths/~1api~1widgets/post/responses/201/headers/Location"))
+
+	resp := JSON(openAPIWidget{ID: "1", Name: "gear"}).SetStatusCode(http.StatusCreated).SetHeader("Location", "/api/widgets/1")
+	_, violations = contract.Check(r, resp)
+	Expect(violations).To(BeEmpty())
+}
+
+func (s *ContractSuite) TestContentType(t *testing.T) {
+	contract := widgetContract()
+	r := httptest.NewRequest("GET", "/api/widgets/7", nil)
+
+	_, violations := contract.Check(r, Text(`{"id": "7", "name": "gear"}`))
+	Expect(violations).To(HaveLen(1))
+	Expect(violations[0].Message).To(Equal(`content type "text/plain; charset=utf-8" is not declared`))
+
+	_, violations = contract.Check(r, Respond([]byte(`{"id": `)).SetHeader("Content-Type", "application/json"))
+	Expect(violations).To(HaveLen(1))
+	Expect(violations[0].Message).To(HavePrefix("response body is not valid JSON"))
+}
+
+func (s *ContractSuite) TestUndeclaredBody(t *testing.T) {
+	_, violations := widgetContract().Check(httptest.NewRequest("GET", "/files/readme", nil), Text("contents"))
+	Expect(violations).To(HaveLen(1))
+	Expect(violations[0].Message).To(Equal("response body is not declared"))
+}
+
+func (s *ContractSuite) TestUndeclaredOperations(t *testing.T) {
+	contract := widgetContract()
+
+	_, violations := contract.Check(httptest.NewRequest("GET", "/api/gadgets", nil), Empty(http.StatusOK))
+	Expect(violations).To(HaveLen(1))
+	Expect(violations[0].Message).To(Equal("path is not declared"))
+
+	_, violations = contract.Check(httptest.NewRequest("PUT", "/api/widgets/7", nil), Empty(http.StatusOK))
+	Expect(violations).To(HaveLen(1))
+	Expect(violations[0].Message).To(Equal("method is not declared"))
+
+	_, violations = contract.Check(httptest.NewRequest("HEAD", "/api/widgets/7", nil), Empty(http.StatusOK))
+	Expect(violations).To(BeEmpty())
+}
+
+func (s *ContractSuite) TestWriteError(t *testing.T) {
+	_, violations := widgetContract().Check(httptest.NewRequest("GET", "/api/widgets/7", nil), failure(errors.New("utoh")))
+	Expect(violations).NotTo(BeEmpty())
+	Expect(violations[0].Message).To(Equal("failed to write response: utoh"))
+}
+
+func (s *ContractSuite) TestMiddleware(t *testing.T) {
+	var reported []Violation
+
+	router := NewRouter()
+	api := router.Group("/api", VerifyContract(widgetContract(), func(r *http.Request, violations []Violation) {
+		reported = append(reported, violations...)
+	}))
+
+	Route(api, "GET", "/widgets/{id}", func(ctx context.Context, in struct{}) (openAPIWidget, error) {
+		return openAPIWidget{ID: Param(RequestFromContext(ctx), "id")}, nil
+	})
+
+	Route(api, "POST", "/widgets", func(ctx context.Context, in openAPICreateWidget) (openAPIWidget, error) {
+		return openAPIWidget{ID: "1", Name: in.Name}, nil
+	}, WithTypedConfig(WithSuccessStatus(http.StatusCreated)))
+
+	w := serveRouter(router, "GET", "/api/widgets/7")
+	Expect(w.Body.String()).To(MatchJSON(`{"id": "7", "name": ""}`))
+	Expect(reported).To(BeEmpty())
+
+	w = httptest.NewRecorder()
+	r := jsonRequest(`{"name": "gear"}`)
+	r.URL.Path = "/api/widgets"
+	router.ServeHTTP(w, r)
+	Expect(w.Code).To(Equal(http.StatusCreated))
+	Expect(reported).To(HaveLen(1))
+	Expect(reported[0].Message).To(Equal("required header Location is missing"))
+}
+
+func (s *ContractSuite) TestSchemaKeywords(t *testing.T) {
+	contract, err := NewContract([]byte(`{
+		"openapi": "3.0.3",
+		"paths": {
+			"/things": {
+				"get": {
+					"responses": {
+						"2XX": {"$ref": "#/components/responses/Things"},
+						"default": {"description": "error"}
+					}
+				}
+			}
+		},
+		"components": {
+			"responses": {
+				"Things": {
+					"description": "things",
+					"content": {"application/*": {"schema": {"type": "array", "maxItems": 2, "items": {"$ref": "#/components/schemas/Thing"}}}}
+				}
+			},
+			"schemas": {
+				"Thing": {
+					"type": "object",
+					"additionalProperties": false,
+					"properties": {
+						"kind": {"type": "string", "enum": ["a", "b"]},
+						"size": {"type": "integer", "minimum": 1, "maximum": 10},
+						"label": {"type": "string", "nullable": true, "pattern": "^[a-z]+$", "maxLength": 4},
+						"value": {"oneOf": [{"type": "string"}, {"type": "number"}]}
+					}
+				}
+			}
+		}
+	}`))
+
+	Expect(err).To(BeNil())
+
+	check := func(body string) []string {
+		resp := Respond([]byte(body)).SetHeader("Content-Type", "application/vnd.things+json").SetStatusCode(http.StatusPartialContent)
+		_, violations := contract.Check(httptest.NewRequest("GET", "/things", nil), resp)
+
+		messages := []string{}
+		for _, v := range violations {
+			messages = append(messages, v.Pointer+" "+v.Message)
+		}
+
+		return messages
+	}
+
+	Expect(check(`[{"kind": "a", "size": 3, "label": null, "value": 1.5}]`)).To(BeEmpty())
+	Expect(check(`[{"kind": "c"}, {"size": 0}, {"size": 2.5}]`)).To(ConsistOf(
+		" expected at most 2 items",
+		"/0/kind value is not one of the allowed values",
+		"/1/size expected a value of at least 1",
+		"/2/size expected integer, got number",
+	))
+	Expect(check(`[{"label": "ABCDE", "value": true, "extra/key": 1}]`)).To(ConsistOf(
+		"/0/label expected at most 4 characters",
+		"/0/label value does not match pattern ^[a-z]+$",
+		"/0/value value matches 0 schemas instead of exactly one",
+		"/0/extra~1key value is not allowed",
+	))
+
+	_, violations := contract.Check(httptest.NewRequest("GET", "/things", nil), Text("oops").SetStatusCode(http.StatusInternalServerError))
+	Expect(violations).To(HaveLen(1))
+	Expect(violations[0].Message).To(Equal("response body is not declared"))
+}
+
+func (s *ContractSuite) TestInvalidDocument(t *testing.T) {
+	_, err := NewContract([]byte(`{"paths": `))
+	Expect(err).To(MatchError(HavePrefix("failed to parse document")))
+
+	_, err = NewContract([]byte(`{"paths": {"things": {}}}`))
+	Expect(err).To(MatchError(`failed to parse document: pattern "things" must begin with /`))
+}
+
+func widgetContract() *Contract {
+	document, err := OpenAPI(openAPIRouter())
+	Expect(err).To(BeNil())
+
+	contract, err := NewContract(document)
+	Expect(err).To(BeNil())
+	return contract
+}
@@ -0,0 +1,176 @@
+package response
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type (
+	// CookieCodec transforms cookie values so that they can be sent to the
+	// client and trusted when they are sent back.
+	CookieCodec interface {
+		// Encode transforms the value of the named cookie.
+		Encode(name, value string) (string, error)
+
+		// Decode recovers the value of the named cookie. An error is
+		// returned if the value was not produced by Encode.
+		Decode(name, value string) (string, error)
+	}
+
+	signedCookieCodec struct {
+		keys [][]byte
+	}
+
+	encryptedCookieCodec struct {
+		aeads []cipher.AEAD
+	}
+)
+
+// ErrInvalidCookie occurs when a cookie value cannot be decoded.
+var ErrInvalidCookie = errors.New("invalid cookie")
+
+// NewSignedCookieCodec creates a codec which appends an HMAC-SHA256 signature
+// to cookie values. The value itself remains readable by the client. Values
+// are signed with the first key and verified against all keys, so keys can
+// be rotated by prepending a new key and later removing the old one.
+func NewSignedCookieCodec(keys ...[]byte) CookieCodec {
+	return &signedCookieCodec{keys: keys}
+}
+
+// Encode signs the value of the named cookie.
+func (c *signedCookieCodec) Encode(name, value string) (string, error) {
+	if len(c.keys) == 0 {
+		return "", errors.New("no signing keys")
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value))
+	signature := base64.RawURLEncoding.EncodeToString(signCookie(c.keys[0], name, payload))
+	return payload + "." + signature, nil
+}
+
+// Decode verifies the signature of the named cookie and returns its value.
+func (c *signedCookieCodec) Decode(name, value string) (string, error) {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return "", ErrInvalidCookie
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	for _, key := range c.keys {
+		if hmac.Equal(signCookie(key, name, parts[0]), signature) {
+			payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+			if err != nil {
+				return "", ErrInvalidCookie
+			}
+
+			return string(payload), nil
+		}
+	}
+
+	return "", ErrInvalidCookie
+}
+
+// signCookie computes the signature of an encoded cookie value. The cookie
+// name is included so a value cannot be moved to another cookie.
+func signCookie(key []byte, name, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name + "|" + payload))
+	return mac.Sum(nil)
+}
+
+// NewEncryptedCookieCodec creates a codec which encrypts and authenticates
+// cookie values with AES-GCM. Each key must be 16, 24, or 32 bytes long.
+// Values are encrypted with the first key and decrypted with any key, so
+// keys can be rotated by prepending a new key and later removing the old one.
+func NewEncryptedCookieCodec(keys ...[]byte) (CookieCodec, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption keys")
+	}
+
+	aeads := []cipher.AEAD{}
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		aeads = append(aeads, aead)
+	}
+
+	return &encryptedCookieCodec{aeads: aeads}, nil
+}
+
+// Encode encrypts the value of the named cookie.
+func (c *encryptedCookieCodec) Encode(name, value string) (string, error) {
+	aead := c.aeads[0]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decode decrypts the value of the named cookie.
+func (c *encryptedCookieCodec) Decode(name, value string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	for _, aead := range c.aeads {
+		if len(sealed) < aead.NonceSize() {
+			continue
+		}
+
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name)); err == nil {
+			return string(plaintext), nil
+		}
+	}
+
+	return "", ErrInvalidCookie
+}
+
+// EncodeCookie returns a copy of the cookie with its value encoded by the
+// given codec. The result is passed to the SetCookie method of a response.
+func EncodeCookie(cookie *http.Cookie, codec CookieCodec) (*http.Cookie, error) {
+	value, err := codec.Encode(cookie.Name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := *cookie
+	encoded.Value = value
+	return &encoded, nil
+}
+
+// DecodeCookie reads the named cookie from the request and decodes its
+// value with the given codec. The error http.ErrNoCookie is returned if the
+// cookie is not present, and ErrInvalidCookie if it fails to decode.
+func DecodeCookie(r *http.Request, name string, codec CookieCodec) (string, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	return codec.Decode(name, cookie.Value)
+}
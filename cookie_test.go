@@ -0,0 +1,161 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type CookieSuite struct{}
+
+func (s *CookieSuite) TestSetCookie(t *testing.T) {
+	resp := Empty(http.StatusOK)
+	resp.SetCookie(&http.Cookie{Name: "session", Value: "abc", Path: "/", HttpOnly: true})
+	resp.SetCookie(&http.Cookie{Name: "theme", Value: "dark", SameSite: http.SameSiteLaxMode})
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers["Set-Cookie"]).To(Equal([]string{
+		"session=abc; Path=/; HttpOnly",
+		"theme=dark; SameSite=Lax",
+	}))
+}
+
+func (s *CookieSuite) TestSetCookieReplaces(t *testing.T) {
+	resp := Empty(http.StatusOK)
+	resp.SetCookie(&http.Cookie{Name: "session", Value: "abc", Path: "/"})
+	resp.SetCookie(&http.Cookie{Name: "session", Value: "abc", Path: "/admin"})
+	resp.SetCookie(&http.Cookie{Name: "session", Value: "def", Path: "/"})
+
+	headers, _, _ := Serialize(resp)
+	Expect(headers["Set-Cookie"]).To(Equal([]string{
+		"session=abc; Path=/admin",
+		"session=def; Path=/",
+	}))
+}
+
+func (s *CookieSuite) TestSetCookieSecureAttributes(t *testing.T) {
+	resp := Empty(http.StatusOK)
+	resp.SetCookie(&http.Cookie{Name: "__Host-embed", Value: "1", Path: "/", Partitioned: true})
+	resp.SetCookie(&http.Cookie{Name: "tracking", Value: "2", SameSite: http.SameSiteNoneMode})
+
+	headers, _, _ := Serialize(resp)
+	Expect(headers["Set-Cookie"]).To(Equal([]string{
+		"__Host-embed=1; Path=/; Secure; Partitioned",
+		"tracking=2; Secure; SameSite=None",
+	}))
+}
+
+func (s *CookieSuite) TestSetCookieInvalid(t *testing.T) {
+	resp := Empty(http.StatusOK)
+	resp.SetCookie(&http.Cookie{Name: "bad name", Value: "abc"})
+
+	Expect(resp.Header("Set-Cookie")).To(BeEmpty())
+	Expect(resp.HeaderErrors()).To(HaveLen(1))
+}
+
+func (s *CookieSuite) TestClearCookie(t *testing.T) {
+	resp := Empty(http.StatusOK)
+	resp.SetCookie(&http.Cookie{Name: "session", Value: "abc", Path: "/", Domain: "example.com"})
+	resp.ClearCookie("session", "/", "example.com")
+
+	headers, _, _ := Serialize(resp)
+	Expect(headers["Set-Cookie"]).To(Equal([]string{
+		"session=; Path=/; Domain=example.com; Expires=Thu, 01 Jan 1970 00:00:01 GMT; Max-Age=0",
+	}))
+}
+
+func (s *CookieSuite) TestSignedCodec(t *testing.T) {
+	codec := NewSignedCookieCodec([]byte("key1"))
+
+	value, err := codec.Encode("session", "user=42")
+	Expect(err).To(BeNil())
+	Expect(value).NotTo(ContainSubstring("="))
+
+	decoded, err := codec.Decode("session", value)
+	Expect(err).To(BeNil())
+	Expect(decoded).To(Equal("user=42"))
+
+	_, err = codec.Decode("other", value)
+	Expect(err).To(Equal(ErrInvalidCookie))
+	_, err = codec.Decode("session", "dXNlcj00Mw."+value[len(value)-43:])
+	Expect(err).To(Equal(ErrInvalidCookie))
+	_, err = codec.Decode("session", "garbage")
+	Expect(err).To(Equal(ErrInvalidCookie))
+
+	_, err = NewSignedCookieCodec().Encode("session", "value")
+	Expect(err).NotTo(BeNil())
+}
+
+func (s *CookieSuite) TestSignedCodecRotation(t *testing.T) {
+	value, _ := NewSignedCookieCodec([]byte("old")).Encode("session", "user=42")
+
+	decoded, err := NewSignedCookieCodec([]byte("new"), []byte("old")).Decode("session", value)
+	Expect(err).To(BeNil())
+	Expect(decoded).To(Equal("user=42"))
+
+	_, err = NewSignedCookieCodec([]byte("new")).Decode("session", value)
+	Expect(err).To(Equal(ErrInvalidCookie))
+}
+
+func (s *CookieSuite) TestEncryptedCodec(t *testing.T) {
+	var (
+		key1 = []byte("0123456789abcdef")
+		key2 = []byte("fedcba9876543210fedcba9876543210")
+	)
+
+	codec, err := NewEncryptedCookieCodec(key1)
+	Expect(err).To(BeNil())
+
+	value, err := codec.Encode("session", "user=42")
+	Expect(err).To(BeNil())
+	Expect(value).NotTo(ContainSubstring("user"))
+
+	decoded, err := codec.Decode("session", value)
+	Expect(err).To(BeNil())
+	Expect(decoded).To(Equal("user=42"))
+
+	_, err = codec.Decode("other", value)
+	Expect(err).To(Equal(ErrInvalidCookie))
+	_, err = codec.Decode("session", "AAAA")
+	Expect(err).To(Equal(ErrInvalidCookie))
+
+	rotated, err := NewEncryptedCookieCodec(key2, key1)
+	Expect(err).To(BeNil())
+	decoded, err = rotated.Decode("session", value)
+	Expect(err).To(BeNil())
+	Expect(decoded).To(Equal("user=42"))
+
+	_, err = NewEncryptedCookieCodec([]byte("short"))
+	Expect(err).NotTo(BeNil())
+	_, err = NewEncryptedCookieCodec()
+	Expect(err).NotTo(BeNil())
+}
+
+func (s *CookieSuite) TestEncodeDecodeCookie(t *testing.T) {
+	codec := NewSignedCookieCodec([]byte("key"))
+
+	handler := func(r *http.Request) Response {
+		if value, err := DecodeCookie(r, "session", codec); err == nil {
+			return Respond([]byte(value))
+		}
+
+		cookie, err := EncodeCookie(&http.Cookie{Name: "session", Value: "user=42", Path: "/"}, codec)
+		Expect(err).To(BeNil())
+		return Empty(http.StatusOK).SetCookie(cookie)
+	}
+
+	headers, _, _ := Serialize(handler(httptest.NewRequest("GET", "/", nil)))
+	cookie, err := http.ParseSetCookie(headers.Get("Set-Cookie"))
+	Expect(err).To(BeNil())
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	_, body, _ := Serialize(handler(r))
+	Expect(body).To(Equal([]byte("user=42")))
+
+	_, err = DecodeCookie(httptest.NewRequest("GET", "/", nil), "session", codec)
+	Expect(err).To(Equal(http.ErrNoCookie))
+}
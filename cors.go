@@ -0,0 +1,237 @@
+package response
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	corsConfig struct {
+		origins     []string
+		patterns    []*regexp.Regexp
+		validators  []OriginValidator
+		methods     []string
+		headers     []string
+		exposed     []string
+		credentials bool
+		maxAge      time.Duration
+	}
+
+	// CORSConfigFunc is a function used to configure the CORS middleware.
+	CORSConfigFunc func(*corsConfig)
+
+	// OriginValidator returns true if the given origin may access the
+	// resource requested by r.
+	OriginValidator func(origin string, r *http.Request) bool
+)
+
+// WithAllowedOrigins allows the given origins. An origin is either an exact
+// value (https://example.com), a pattern with a single wildcard such as
+// https://*.example.com, or * to allow any origin.
+func WithAllowedOrigins(origins ...string) CORSConfigFunc {
+	return func(c *corsConfig) { c.origins = append(c.origins, origins...) }
+}
+
+// WithAllowedOriginPattern allows any origin which matches the given
+// regular expression. The expression should be anchored.
+func WithAllowedOriginPattern(pattern *regexp.Regexp) CORSConfigFunc {
+	return func(c *corsConfig) { c.patterns = append(c.patterns, pattern) }
+}
+
+// WithOriginValidator allows any origin for which the given function
+// returns true.
+func WithOriginValidator(f OriginValidator) CORSConfigFunc {
+	return func(c *corsConfig) { c.validators = append(c.validators, f) }
+}
+
+// WithAllowedMethods sets the methods allowed in cross-origin requests.
+// The default methods are GET, HEAD, and POST.
+func WithAllowedMethods(methods ...string) CORSConfigFunc {
+	return func(c *corsConfig) { c.methods = methods }
+}
+
+// WithAllowedHeaders sets the request headers allowed in cross-origin
+// requests. The value * allows any requested header. The default headers
+// are Accept, Accept-Language, Content-Language, and Content-Type.
+func WithAllowedHeaders(headers ...string) CORSConfigFunc {
+	return func(c *corsConfig) { c.headers = headers }
+}
+
+// WithExposedHeaders sets the response headers which are made available
+// to scripts of an allowed origin.
+func WithExposedHeaders(headers ...string) CORSConfigFunc {
+	return func(c *corsConfig) { c.exposed = headers }
+}
+
+// WithCredentials allows cross-origin requests to include credentials
+// (cookies and authorization headers). The requesting origin is echoed back
+// as required by the specification. This option cannot be combined with the
+// * origin, as that would allow every site to make credentialed requests.
+func WithCredentials() CORSConfigFunc {
+	return func(c *corsConfig) { c.credentials = true }
+}
+
+// WithPreflightMaxAge sets the duration for which clients may cache the
+// result of a preflight request.
+func WithPreflightMaxAge(maxAge time.Duration) CORSConfigFunc {
+	return func(c *corsConfig) { c.maxAge = maxAge }
+}
+
+// CORS creates a middleware which implements cross-origin resource sharing.
+// Preflight requests are answered directly with an empty 204 response and
+// are not passed to the wrapped handler. Responses to other requests from
+// an allowed origin are decorated with the appropriate access control
+// headers. This function panics if credentials are allowed for any origin.
+func CORS(configs ...CORSConfigFunc) Middleware {
+	config := &corsConfig{
+		methods: []string{"GET", "HEAD", "POST"},
+		headers: []string{"Accept", "Accept-Language", "Content-Language", "Content-Type"},
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	if config.credentials && containsFold(config.origins, "*") {
+		panic("CORS credentials cannot be allowed for the * origin")
+	}
+
+	return func(f HandlerFunc) HandlerFunc {
+		return func(r *http.Request) Response {
+			origin := r.Header.Get("Origin")
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				return config.preflight(r, origin)
+			}
+
+			resp := f(r)
+			resp.AddHeader("Vary", "Origin")
+
+			if origin != "" && config.allowOrigin(origin, r) {
+				config.setOriginHeaders(resp, origin)
+
+				if len(config.exposed) > 0 {
+					resp.SetHeader("Access-Control-Expose-Headers", strings.Join(config.exposed, ", "))
+				}
+			}
+
+			return resp
+		}
+	}
+}
+
+// preflight constructs the response to a preflight request. The access
+// control headers are omitted if the origin, method, or any of the requested
+// headers are not allowed, which causes the client to reject the request.
+func (c *corsConfig) preflight(r *http.Request, origin string) Response {
+	resp := Empty(http.StatusNoContent)
+	resp.AddHeader("Vary", "Origin")
+	resp.AddHeader("Vary", "Access-Control-Request-Method")
+	resp.AddHeader("Vary", "Access-Control-Request-Headers")
+
+	if origin == "" || !c.allowOrigin(origin, r) {
+		return resp
+	}
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	if !containsFold(c.methods, method) {
+		return resp
+	}
+
+	requested := []string{}
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			requested = append(requested, header)
+		}
+	}
+
+	for _, header := range requested {
+		if !containsFold(c.headers, "*") && !containsFold(c.headers, header) {
+			return resp
+		}
+	}
+
+	c.setOriginHeaders(resp, origin)
+	resp.SetHeader("Access-Control-Allow-Methods", strings.Join(c.methods, ", "))
+
+	if len(requested) > 0 {
+		resp.SetHeader("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+
+	if c.maxAge > 0 {
+		resp.SetHeader("Access-Control-Max-Age", strconv.Itoa(int(c.maxAge/time.Second)))
+	}
+
+	return resp
+}
+
+// setOriginHeaders sets the headers which grant access to the given origin.
+func (c *corsConfig) setOriginHeaders(resp Response, origin string) {
+	if c.credentials {
+		resp.SetHeader("Access-Control-Allow-Credentials", "true")
+	}
+
+	if len(c.patterns) == 0 && len(c.validators) == 0 && containsFold(c.origins, "*") {
+		resp.SetHeader("Access-Control-Allow-Origin", "*")
+	} else {
+		resp.SetHeader("Access-Control-Allow-Origin", origin)
+	}
+}
+
+// allowOrigin returns true if the origin matches any configured rule.
+func (c *corsConfig) allowOrigin(origin string, r *http.Request) bool {
+	for _, allowed := range c.origins {
+		if matchOrigin(allowed, origin) {
+			return true
+		}
+	}
+
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	for _, f := range c.validators {
+		if f(origin, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchOrigin returns true if the origin matches the given exact value or
+// wildcard pattern.
+func matchOrigin(allowed, origin string) bool {
+	if allowed == "*" {
+		return true
+	}
+
+	i := strings.Index(allowed, "*")
+	if i < 0 {
+		return strings.EqualFold(allowed, origin)
+	}
+
+	prefix, suffix := strings.ToLower(allowed[:i]), strings.ToLower(allowed[i+1:])
+	origin = strings.ToLower(origin)
+
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) &&
+		strings.HasSuffix(origin, suffix)
+}
+
+// containsFold returns true if the list contains the given value, ignoring
+// case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,153 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type CORSSuite struct{}
+
+func (s *CORSSuite) TestPreflight(t *testing.T) {
+	called := false
+	handler := CORS(
+		WithAllowedOrigins("https://example.com"),
+		WithAllowedMethods("GET", "PUT"),
+		WithAllowedHeaders("Content-Type", "X-Request-ID"),
+		WithPreflightMaxAge(10*time.Minute),
+	)(func(r *http.Request) Response {
+		called = true
+		return Empty(http.StatusOK)
+	})
+
+	r := httptest.NewRequest("OPTIONS", "/", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", "PUT")
+	r.Header.Set("Access-Control-Request-Headers", "content-type, x-request-id")
+
+	headers, _, err := Serialize(handler(r))
+	Expect(err).To(BeNil())
+	Expect(called).To(BeFalse())
+	Expect(headers.Get("Access-Control-Allow-Origin")).To(Equal("https://example.com"))
+	Expect(headers.Get("Access-Control-Allow-Methods")).To(Equal("GET, PUT"))
+	Expect(headers.Get("Access-Control-Allow-Headers")).To(Equal("content-type, x-request-id"))
+	Expect(headers.Get("Access-Control-Max-Age")).To(Equal("600"))
+	Expect(headers.Get("Access-Control-Allow-Credentials")).To(BeEmpty())
+	Expect(headers["Vary"]).To(Equal([]string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}))
+}
+
+func (s *CORSSuite) TestPreflightRejected(t *testing.T) {
+	handler := CORS(
+		WithAllowedOrigins("https://example.com"),
+		WithAllowedHeaders("Content-Type"),
+	)(nil)
+
+	for _, values := range [][]string{
+		{"https://evil.com", "GET", ""},
+		{"https://example.com", "DELETE", ""},
+		{"https://example.com", "GET", "X-Secret"},
+	} {
+		r := httptest.NewRequest("OPTIONS", "/", nil)
+		r.Header.Set("Origin", values[0])
+		r.Header.Set("Access-Control-Request-Method", values[1])
+		r.Header.Set("Access-Control-Request-Headers", values[2])
+
+		resp := handler(r)
+		Expect(resp.StatusCode()).To(Equal(http.StatusNoContent))
+		Expect(resp.Header("Access-Control-Allow-Origin")).To(BeEmpty())
+		Expect(resp.Header("Access-Control-Allow-Methods")).To(BeEmpty())
+	}
+}
+
+func (s *CORSSuite) TestSimpleRequest(t *testing.T) {
+	handler := CORS(
+		WithAllowedOrigins("https://example.com"),
+		WithExposedHeaders("X-Request-ID", "X-Total-Count"),
+	)(func(r *http.Request) Response {
+		return Empty(http.StatusOK).AddHeader("Vary", "Accept-Encoding")
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Origin", "https://example.com")
+
+	headers, _, _ := Serialize(handler(r))
+	Expect(headers.Get("Access-Control-Allow-Origin")).To(Equal("https://example.com"))
+	Expect(headers.Get("Access-Control-Expose-Headers")).To(Equal("X-Request-ID, X-Total-Count"))
+	Expect(headers["Vary"]).To(Equal([]string{"Accept-Encoding", "Origin"}))
+
+	r.Header.Set("Origin", "https://evil.com")
+	headers, _, _ = Serialize(handler(r))
+	Expect(headers.Get("Access-Control-Allow-Origin")).To(BeEmpty())
+	Expect(headers.Get("Access-Control-Expose-Headers")).To(BeEmpty())
+	Expect(headers["Vary"]).To(Equal([]string{"Accept-Encoding", "Origin"}))
+}
+
+func (s *CORSSuite) TestWildcard(t *testing.T) {
+	handler := CORS(WithAllowedOrigins("*"))(func(r *http.Request) Response {
+		return Empty(http.StatusOK)
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Origin", "https://anywhere.com")
+	Expect(handler(r).Header("Access-Control-Allow-Origin")).To(Equal("*"))
+}
+
+func (s *CORSSuite) TestWildcardCredentials(t *testing.T) {
+	Expect(func() { CORS(WithAllowedOrigins("*"), WithCredentials()) }).To(Panic())
+	Expect(func() { CORS(WithAllowedOrigins("https://example.com", "*"), WithCredentials()) }).To(Panic())
+}
+
+func (s *CORSSuite) TestCredentials(t *testing.T) {
+	handler := CORS(WithAllowedOrigins("https://*.example.com"), WithCredentials())(func(r *http.Request) Response {
+		return Empty(http.StatusOK)
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+
+	resp := handler(r)
+	Expect(resp.Header("Access-Control-Allow-Origin")).To(Equal("https://app.example.com"))
+	Expect(resp.Header("Access-Control-Allow-Credentials")).To(Equal("true"))
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Origin", "https://evil.com")
+
+	resp = handler(r)
+	Expect(resp.Header("Access-Control-Allow-Origin")).To(BeEmpty())
+	Expect(resp.Header("Access-Control-Allow-Credentials")).To(BeEmpty())
+}
+
+func (s *CORSSuite) TestOriginRules(t *testing.T) {
+	handler := CORS(
+		WithAllowedOrigins("https://*.example.com"),
+		WithAllowedOriginPattern(regexp.MustCompile(`^https://app-\d+\.test$`)),
+		WithOriginValidator(func(origin string, r *http.Request) bool {
+			return strings.HasSuffix(origin, ".internal") && r.URL.Path == "/internal"
+		}),
+	)(func(r *http.Request) Response {
+		return Empty(http.StatusOK)
+	})
+
+	for origin, expected := range map[string]bool{
+		"https://api.example.com":  true,
+		"https://API.example.com":  true,
+		"https://.example.com":     false,
+		"http://api.example.com":   false,
+		"https://example.com":      false,
+		"https://app-12.test":      true,
+		"https://app-x.test":       false,
+		"https://svc.internal":     true,
+		"https://svc.internal.com": false,
+	} {
+		r := httptest.NewRequest("GET", "/internal", nil)
+		r.Header.Set("Origin", origin)
+
+		allowed := handler(r).Header("Access-Control-Allow-Origin") != ""
+		Expect(allowed).To(Equal(expected), origin)
+	}
+}
@@ -0,0 +1,141 @@
+package response
+
+import (
+	"strings"
+)
+
+type (
+	// CSPDirective is the name of a Content-Security-Policy directive.
+	CSPDirective string
+
+	// CSPSource is a single source expression of a Content-Security-Policy
+	// directive.
+	CSPSource string
+
+	// ContentSecurityPolicy builds the value of a Content-Security-Policy
+	// header from typed directives and sources.
+	ContentSecurityPolicy struct {
+		directives []cspDirective
+	}
+
+	cspDirective struct {
+		name    CSPDirective
+		sources []CSPSource
+	}
+)
+
+// Fetch, document, and navigation directives.
+const (
+	DirectiveDefaultSrc              CSPDirective = "default-src"
+	DirectiveScriptSrc               CSPDirective = "script-src"
+	DirectiveStyleSrc                CSPDirective = "style-src"
+	DirectiveImgSrc                  CSPDirective = "img-src"
+	DirectiveConnectSrc              CSPDirective = "connect-src"
+	DirectiveFontSrc                 CSPDirective = "font-src"
+	DirectiveObjectSrc               CSPDirective = "object-src"
+	DirectiveMediaSrc                CSPDirective = "media-src"
+	DirectiveFrameSrc                CSPDirective = "frame-src"
+	DirectiveChildSrc                CSPDirective = "child-src"
+	DirectiveWorkerSrc               CSPDirective = "worker-src"
+	DirectiveManifestSrc             CSPDirective = "manifest-src"
+	DirectiveBaseURI                 CSPDirective = "base-uri"
+	DirectiveFormAction              CSPDirective = "form-action"
+	DirectiveFrameAncestors          CSPDirective = "frame-ancestors"
+	DirectiveSandbox                 CSPDirective = "sandbox"
+	DirectiveUpgradeInsecureRequests CSPDirective = "upgrade-insecure-requests"
+	DirectiveReportURI               CSPDirective = "report-uri"
+	DirectiveReportTo                CSPDirective = "report-to"
+)
+
+// Keyword and scheme sources.
+const (
+	SourceSelf           CSPSource = "'self'"
+	SourceNone           CSPSource = "'none'"
+	SourceUnsafeInline   CSPSource = "'unsafe-inline'"
+	SourceUnsafeEval     CSPSource = "'unsafe-eval'"
+	SourceUnsafeHashes   CSPSource = "'unsafe-hashes'"
+	SourceWasmUnsafeEval CSPSource = "'wasm-unsafe-eval'"
+	SourceStrictDynamic  CSPSource = "'strict-dynamic'"
+	SourceReportSample   CSPSource = "'report-sample'"
+	SourceData           CSPSource = "data:"
+	SourceBlob           CSPSource = "blob:"
+	SourceHTTPS          CSPSource = "https:"
+
+	// SourceNonce is replaced by a nonce source containing a fresh value
+	// for each request. The value is available to handlers via CSPNonce.
+	SourceNonce CSPSource = "'nonce'"
+)
+
+// SourceHost creates a host source (such as https://cdn.example.com or
+// *.example.com).
+func SourceHost(host string) CSPSource {
+	return CSPSource(host)
+}
+
+// SourceHash creates a hash source from the name of a hash algorithm
+// (sha256, sha384, or sha512) and the base64-encoded digest.
+func SourceHash(algorithm, digest string) CSPSource {
+	return CSPSource("'" + algorithm + "-" + digest + "'")
+}
+
+// NewContentSecurityPolicy creates an empty policy.
+func NewContentSecurityPolicy() *ContentSecurityPolicy {
+	return &ContentSecurityPolicy{}
+}
+
+// Add appends the given sources to a directive. Directives without sources
+// (such as upgrade-insecure-requests) may be added without any sources.
+func (p *ContentSecurityPolicy) Add(directive CSPDirective, sources ...CSPSource) *ContentSecurityPolicy {
+	for i, d := range p.directives {
+		if d.name == directive {
+			p.directives[i].sources = append(d.sources, sources...)
+			return p
+		}
+	}
+
+	p.directives = append(p.directives, cspDirective{directive, sources})
+	return p
+}
+
+// UsesNonce returns true if any directive contains SourceNonce.
+func (p *ContentSecurityPolicy) UsesNonce() bool {
+	for _, d := range p.directives {
+		for _, source := range d.sources {
+			if source == SourceNonce {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Build serializes the policy. Occurrences of SourceNonce are replaced by
+// a nonce source with the given value, or omitted if the value is empty.
+func (p *ContentSecurityPolicy) Build(nonce string) string {
+	directives := []string{}
+	for _, d := range p.directives {
+		parts := []string{string(d.name)}
+
+		for _, source := range d.sources {
+			if source == SourceNonce {
+				if nonce == "" {
+					continue
+				}
+
+				source = CSPSource("'nonce-" + nonce + "'")
+			}
+
+			parts = append(parts, string(source))
+		}
+
+		directives = append(directives, strings.Join(parts, " "))
+	}
+
+	return strings.Join(directives, "; ")
+}
+
+// String serializes the policy without nonce sources.
+func (p *ContentSecurityPolicy) String() string {
+	return p.Build("")
+}
@@ -0,0 +1,36 @@
+package response
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type CSPSuite struct{}
+
+func (s *CSPSuite) TestBuild(t *testing.T) {
+	policy := NewContentSecurityPolicy().
+		Add(DirectiveDefaultSrc, SourceSelf).
+		Add(DirectiveScriptSrc, SourceSelf, SourceHost("https://cdn.example.com")).
+		Add(DirectiveImgSrc, SourceSelf, SourceData).
+		Add(DirectiveScriptSrc, SourceHash("sha256", "abc=")).
+		Add(DirectiveUpgradeInsecureRequests)
+
+	Expect(policy.UsesNonce()).To(BeFalse())
+	Expect(policy.String()).To(Equal(
+		"default-src 'self'; " +
+			"script-src 'self' https://cdn.example.com 'sha256-abc='; " +
+			"img-src 'self' data:; " +
+			"upgrade-insecure-requests",
+	))
+}
+
+func (s *CSPSuite) TestBuildNonce(t *testing.T) {
+	policy := NewContentSecurityPolicy().
+		Add(DirectiveScriptSrc, SourceNonce, SourceStrictDynamic).
+		Add(DirectiveObjectSrc, SourceNone)
+
+	Expect(policy.UsesNonce()).To(BeTrue())
+	Expect(policy.Build("r4nd0m")).To(Equal("script-src 'nonce-r4nd0m' 'strict-dynamic'; object-src 'none'"))
+	Expect(policy.String()).To(Equal("script-src 'strict-dynamic'; object-src 'none'"))
+}
@@ -0,0 +1,538 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	// DiffKind describes how a value differs between two responses.
+	DiffKind string
+
+	// Difference describes a single difference between two responses. The
+	// path identifies the differing value: the status code, a header name, a
+	// JSON pointer or element path into the body, or a line of the body. The
+	// values A and B are empty for added and removed values, respectively.
+	Difference struct {
+		Kind DiffKind
+		Path string
+		A    string
+		B    string
+	}
+
+	// DiffReport describes the differences between two responses. Errors
+	// which occur while writing either response are also reported.
+	DiffReport struct {
+		StatusCode *Difference
+		Headers    []Difference
+		Body       []Difference
+		ErrA       error
+		ErrB       error
+	}
+
+	diffConfig struct {
+		ignored map[string]struct{}
+	}
+
+	// DiffConfigFunc is a function used to configure the Diff function.
+	DiffConfigFunc func(*diffConfig)
+
+	// xmlNode is a simplified XML element used for structural comparison.
+	xmlNode struct {
+		name     string
+		attrs    []string
+		text     string
+		children []*xmlNode
+	}
+)
+
+// The ways in which a value can differ between two responses.
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// unsplittableHeaders contains headers whose values contain commas which
+// do not separate list elements.
+var unsplittableHeaders = map[string]struct{}{
+	"Date":                {},
+	"Expires":             {},
+	"Last-Modified":       {},
+	"If-Modified-Since":   {},
+	"If-Unmodified-Since": {},
+	"Retry-After":         {},
+	"Set-Cookie":          {},
+	"Www-Authenticate":    {},
+	"Proxy-Authenticate":  {},
+}
+
+// WithDiffIgnoredHeaders excludes the given headers from the comparison.
+// The Date header is always ignored.
+func WithDiffIgnoredHeaders(names ...string) DiffConfigFunc {
+	return func(c *diffConfig) {
+		for _, name := range names {
+			c.ignored[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+}
+
+// Diff serializes both responses and compares them semantically. Headers
+// are compared after normalizing the case of their names, the order of
+// their values, and the way list values are split across fields. Bodies
+// are compared structurally if both are JSON or both are XML, line by line
+// if both are text, and byte-wise otherwise. Both responses are consumed.
+func Diff(a, b Response, configs ...DiffConfigFunc) *DiffReport {
+	config := &diffConfig{ignored: map[string]struct{}{"Date": {}}}
+	for _, f := range configs {
+		f(config)
+	}
+
+	statusA, statusB := a.StatusCode(), b.StatusCode()
+	headerA, bodyA, errA := Serialize(a)
+	headerB, bodyB, errB := Serialize(b)
+
+	report := &DiffReport{ErrA: errA, ErrB: errB}
+	if statusA != statusB {
+		report.StatusCode = &Difference{DiffChanged, "status", fmt.Sprintf("%d", statusA), fmt.Sprintf("%d", statusB)}
+	}
+
+	report.Headers = diffHeaders(normalizeHeaders(headerA, config), normalizeHeaders(headerB, config))
+	report.Body = diffBodies(headerA.Get("Content-Type"), bodyA, headerB.Get("Content-Type"), bodyB)
+	return report
+}
+
+// Equal returns true if no differences or errors were found.
+func (r *DiffReport) Equal() bool {
+	return r.StatusCode == nil && len(r.Headers) == 0 && len(r.Body) == 0 && r.ErrA == nil && r.ErrB == nil
+}
+
+// String renders the report with one difference per line.
+func (r *DiffReport) String() string {
+	lines := []string{}
+	if r.ErrA != nil {
+		lines = append(lines, fmt.Sprintf("error writing a: %s", r.ErrA))
+	}
+
+	if r.ErrB != nil {
+		lines = append(lines, fmt.Sprintf("error writing b: %s", r.ErrB))
+	}
+
+	if r.StatusCode != nil {
+		lines = append(lines, r.StatusCode.String())
+	}
+
+	for _, d := range r.Headers {
+		lines = append(lines, "header "+d.String())
+	}
+
+	for _, d := range r.Body {
+		lines = append(lines, "body "+d.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// String renders the difference.
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("%s: added %q", d.Path, d.B)
+	case DiffRemoved:
+		return fmt.Sprintf("%s: removed %q", d.Path, d.A)
+	}
+
+	return fmt.Sprintf("%s: %q != %q", d.Path, d.A, d.B)
+}
+
+// normalizeHeaders splits list values of each header that is not ignored
+// into trimmed elements and sorts them.
+func normalizeHeaders(header http.Header, config *diffConfig) map[string][]string {
+	normalized := map[string][]string{}
+
+	for name, values := range header {
+		name = http.CanonicalHeaderKey(name)
+		if _, ok := config.ignored[name]; ok {
+			continue
+		}
+
+		for _, value := range values {
+			if _, ok := unsplittableHeaders[name]; ok {
+				normalized[name] = append(normalized[name], strings.TrimSpace(value))
+				continue
+			}
+
+			for _, element := range splitList(value) {
+				if element = strings.TrimSpace(element); element != "" {
+					normalized[name] = append(normalized[name], element)
+				}
+			}
+		}
+	}
+
+	for _, elements := range normalized {
+		sort.Strings(elements)
+	}
+
+	return normalized
+}
+
+// splitList splits a header value on commas which do not occur within a
+// quoted string.
+func splitList(value string) []string {
+	parts := []string{}
+	start, quoted, escaped := 0, false, false
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+// diffHeaders compares two sets of normalized headers.
+func diffHeaders(a, b map[string][]string) []Difference {
+	names := map[string]struct{}{}
+	for name := range a {
+		names[name] = struct{}{}
+	}
+
+	for name := range b {
+		names[name] = struct{}{}
+	}
+
+	sorted := []string{}
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+
+	sort.Strings(sorted)
+
+	differences := []Difference{}
+	for _, name := range sorted {
+		valuesA, okA := a[name]
+		valuesB, okB := b[name]
+		joinedA, joinedB := strings.Join(valuesA, ", "), strings.Join(valuesB, ", ")
+
+		switch {
+		case !okA:
+			differences = append(differences, Difference{DiffAdded, name, "", joinedB})
+		case !okB:
+			differences = append(differences, Difference{DiffRemoved, name, joinedA, ""})
+		case joinedA != joinedB:
+			differences = append(differences, Difference{DiffChanged, name, joinedA, joinedB})
+		}
+	}
+
+	return differences
+}
+
+// diffBodies compares two bodies using the most specific comparison which
+// applies to both.
+func diffBodies(contentTypeA string, a []byte, contentTypeB string, b []byte) []Difference {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+
+	if isJSONMediaType(contentTypeA) && isJSONMediaType(contentTypeB) {
+		valueA, errA := decodeJSONValue(a)
+		valueB, errB := decodeJSONValue(b)
+		if errA == nil && errB == nil {
+			differences := []Difference{}
+			diffJSON("", valueA, valueB, &differences)
+			return differences
+		}
+	}
+
+	if isXMLMediaType(contentTypeA) && isXMLMediaType(contentTypeB) {
+		nodeA, errA := parseXMLNode(a)
+		nodeB, errB := parseXMLNode(b)
+		if errA == nil && errB == nil {
+			differences := []Difference{}
+			diffXML("/"+nodeA.name, nodeA, nodeB, &differences)
+			return differences
+		}
+	}
+
+	if utf8.Valid(a) && utf8.Valid(b) {
+		return diffLines(strings.Split(string(a), "\n"), strings.Split(string(b), "\n"))
+	}
+
+	return []Difference{{DiffChanged, "bytes", fmt.Sprintf("%d bytes", len(a)), fmt.Sprintf("%d bytes", len(b))}}
+}
+
+// isXMLMediaType returns true if the given content type denotes XML.
+func isXMLMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml"))
+}
+
+// decodeJSONValue decodes a JSON document preserving the text of numbers.
+func decodeJSONValue(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// diffJSON compares two decoded JSON values recursively. Differences are
+// identified by JSON pointers (see RFC 6901), so a difference of the entire
+// document is identified by the empty pointer.
+func diffJSON(pointer string, a, b interface{}, differences *[]Difference) {
+	switch valueA := a.(type) {
+	case map[string]interface{}:
+		if valueB, ok := b.(map[string]interface{}); ok {
+			keys := map[string]struct{}{}
+			for key := range valueA {
+				keys[key] = struct{}{}
+			}
+
+			for key := range valueB {
+				keys[key] = struct{}{}
+			}
+
+			sorted := []string{}
+			for key := range keys {
+				sorted = append(sorted, key)
+			}
+
+			sort.Strings(sorted)
+
+			for _, key := range sorted {
+				path := pointer + "/" + escapePointer(key)
+				childA, okA := valueA[key]
+				childB, okB := valueB[key]
+
+				switch {
+				case !okA:
+					*differences = append(*differences, Difference{DiffAdded, path, "", compactJSON(childB)})
+				case !okB:
+					*differences = append(*differences, Difference{DiffRemoved, path, compactJSON(childA), ""})
+				default:
+					diffJSON(path, childA, childB, differences)
+				}
+			}
+
+			return
+		}
+
+	case []interface{}:
+		if valueB, ok := b.([]interface{}); ok {
+			for i := 0; i < len(valueA) || i < len(valueB); i++ {
+				path := fmt.Sprintf("%s/%d", pointer, i)
+
+				switch {
+				case i >= len(valueA):
+					*differences = append(*differences, Difference{DiffAdded, path, "", compactJSON(valueB[i])})
+				case i >= len(valueB):
+					*differences = append(*differences, Difference{DiffRemoved, path, compactJSON(valueA[i]), ""})
+				default:
+					diffJSON(path, valueA[i], valueB[i], differences)
+				}
+			}
+
+			return
+		}
+	}
+
+	if !jsonEqual(a, b) {
+		*differences = append(*differences, Difference{DiffChanged, pointer, compactJSON(a), compactJSON(b)})
+	}
+}
+
+// compactJSON serializes the value without whitespace.
+func compactJSON(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// parseXMLNode parses the root element of an XML document. Comments and
+// processing instructions are discarded along with whitespace surrounding
+// character data.
+func parseXMLNode(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	stack := []*xmlNode{}
+	var root *xmlNode
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: xmlName(t.Name)}
+			for _, attr := range t.Attr {
+				if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+					node.attrs = append(node.attrs, fmt.Sprintf("%s=%q", xmlName(attr.Name), attr.Value))
+				}
+			}
+
+			sort.Strings(node.attrs)
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+
+			stack = append(stack, node)
+
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("document has no root element")
+	}
+
+	return root, nil
+}
+
+// xmlName returns the local name of an element or attribute qualified by
+// its namespace URI, if any.
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return fmt.Sprintf("{%s}%s", name.Space, name.Local)
+}
+
+// diffXML compares two XML elements recursively. Differences are identified
+// by element paths with one-based sibling indexes.
+func diffXML(path string, a, b *xmlNode, differences *[]Difference) {
+	if a.name != b.name {
+		*differences = append(*differences, Difference{DiffChanged, path, a.name, b.name})
+		return
+	}
+
+	if attrsA, attrsB := strings.Join(a.attrs, " "), strings.Join(b.attrs, " "); attrsA != attrsB {
+		*differences = append(*differences, Difference{DiffChanged, path + "/@", attrsA, attrsB})
+	}
+
+	if textA, textB := strings.TrimSpace(a.text), strings.TrimSpace(b.text); textA != textB {
+		*differences = append(*differences, Difference{DiffChanged, path + "/text()", textA, textB})
+	}
+
+	for i := 0; i < len(a.children) || i < len(b.children); i++ {
+		switch {
+		case i >= len(a.children):
+			*differences = append(*differences, Difference{DiffAdded, fmt.Sprintf("%s/%s[%d]", path, b.children[i].name, i+1), "", b.children[i].name})
+		case i >= len(b.children):
+			*differences = append(*differences, Difference{DiffRemoved, fmt.Sprintf("%s/%s[%d]", path, a.children[i].name, i+1), a.children[i].name, ""})
+		default:
+			diffXML(fmt.Sprintf("%s/%s[%d]", path, a.children[i].name, i+1), a.children[i], b.children[i], differences)
+		}
+	}
+}
+
+// maxLineDiffCells bounds the size of the table used to compute a longest
+// common subsequence of lines. Larger inputs are compared line by line.
+const maxLineDiffCells = 1 << 22
+
+// diffLines compares two texts line by line. Lines which are not part of a
+// longest common subsequence are reported as added or removed, and a
+// removal immediately followed by an addition is reported as a change.
+// Differences are identified by the (one-based) line number in a, or in b
+// for added lines.
+func diffLines(a, b []string) []Difference {
+	if len(a)*len(b) > maxLineDiffCells {
+		differences := []Difference{}
+		for i := 0; i < len(a) || i < len(b); i++ {
+			switch {
+			case i >= len(a):
+				differences = append(differences, Difference{DiffAdded, fmt.Sprintf("line %d", i+1), "", b[i]})
+			case i >= len(b):
+				differences = append(differences, Difference{DiffRemoved, fmt.Sprintf("line %d", i+1), a[i], ""})
+			case a[i] != b[i]:
+				differences = append(differences, Difference{DiffChanged, fmt.Sprintf("line %d", i+1), a[i], b[i]})
+			}
+		}
+
+		return differences
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	differences := []Difference{}
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+
+		case i < len(a) && (j >= len(b) || lengths[i+1][j] >= lengths[i][j+1]):
+			if j < len(b) && lengths[i+1][j+1] == lengths[i][j] {
+				// Neither line is part of the common subsequence
+				differences = append(differences, Difference{DiffChanged, fmt.Sprintf("line %d", i+1), a[i], b[j]})
+				i++
+				j++
+				continue
+			}
+
+			differences = append(differences, Difference{DiffRemoved, fmt.Sprintf("line %d", i+1), a[i], ""})
+			i++
+
+		default:
+			differences = append(differences, Difference{DiffAdded, fmt.Sprintf("line %d", j+1), "", b[j]})
+			j++
+		}
+	}
+
+	return differences
+}
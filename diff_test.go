@@ -0,0 +1,149 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type DiffSuite struct{}
+
+func (s *DiffSuite) TestEqual(t *testing.T) {
+	a := JSON(map[string]interface{}{"a": 1, "b": []int{1, 2}})
+	b := Respond([]byte(`{ "b": [1, 2.0], "a": 1 }`)).SetHeader("Content-Type", "application/json")
+	b.SetHeader("Content-Length", "17")
+	b.SetHeader("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+
+	report := Diff(a, b)
+	Expect(report.Equal()).To(BeTrue(), report.String())
+	Expect(report.String()).To(BeEmpty())
+}
+
+func (s *DiffSuite) TestStatusCode(t *testing.T) {
+	report := Diff(Empty(http.StatusOK), Empty(http.StatusCreated))
+	Expect(report.Equal()).To(BeFalse())
+	Expect(report.StatusCode).To(Equal(&Difference{DiffChanged, "status", "200", "201"}))
+	Expect(report.String()).To(Equal(`status: "200" != "201"`))
+}
+
+func (s *DiffSuite) TestHeaders(t *testing.T) {
+	a := Empty(http.StatusOK)
+	a.AddHeader("Vary", "Accept-Encoding, Origin")
+	a.AddHeader("X-Removed", "gone")
+	a.AddHeader("Cache-Control", "no-cache")
+	a.AddHeader("Set-Cookie", "a=1; Expires=Mon, 02 Jan 2006 15:04:05 GMT")
+	a.AddHeader("X-Request-Id", "1")
+
+	b := Empty(http.StatusOK)
+	b.AddHeader("vary", "Origin")
+	b.AddHeader("Vary", "Accept-Encoding")
+	b.AddHeader("X-Added", `"quoted, value"`)
+	b.AddHeader("Cache-Control", "no-store")
+	b.AddHeader("Set-Cookie", "a=1; Expires=Mon, 02 Jan 2006 15:04:05 GMT")
+	b.AddHeader("X-Request-Id", "2")
+
+	report := Diff(a, b, WithDiffIgnoredHeaders("x-request-id"))
+	Expect(report.Headers).To(Equal([]Difference{
+		{DiffChanged, "Cache-Control", "no-cache", "no-store"},
+		{DiffAdded, "X-Added", "", `"quoted, value"`},
+		{DiffRemoved, "X-Removed", "gone", ""},
+	}))
+
+	Expect(report.String()).To(Equal(`header Cache-Control: "no-cache" != "no-store"
+header X-Added: added "\"quoted, value\""
+header X-Removed: removed "gone"`))
+}
+
+func (s *DiffSuite) TestJSON(t *testing.T) {
+	a := JSON(map[string]interface{}{
+		"name":  "bob",
+		"age":   30,
+		"tags":  []string{"a", "b", "c"},
+		"extra": map[string]string{"x/y": "1"},
+	})
+
+	b := JSON(map[string]interface{}{
+		"name": "alice",
+		"age":  30.0,
+		"tags": []string{"a", "c"},
+		"new":  true,
+	})
+
+	report := Diff(a, b, WithDiffIgnoredHeaders("Content-Length"))
+	Expect(report.Headers).To(BeEmpty())
+	Expect(report.Body).To(Equal([]Difference{
+		{DiffRemoved, "/extra", `{"x/y":"1"}`, ""},
+		{DiffChanged, "/name", `"bob"`, `"alice"`},
+		{DiffAdded, "/new", "", "true"},
+		{DiffChanged, "/tags/1", `"b"`, `"c"`},
+		{DiffRemoved, "/tags/2", `"c"`, ""},
+	}))
+}
+
+func (s *DiffSuite) TestJSONRoot(t *testing.T) {
+	report := Diff(JSON([]int{1}), JSON(map[string]int{"a": 1}), WithDiffIgnoredHeaders("Content-Length"))
+	Expect(report.Body).To(Equal([]Difference{{DiffChanged, "", "[1]", `{"a":1}`}}))
+}
+
+func (s *DiffSuite) TestJSONRootScalar(t *testing.T) {
+	report := Diff(JSON(1), JSON("one"), WithDiffIgnoredHeaders("Content-Length"))
+	Expect(report.Body).To(Equal([]Difference{{DiffChanged, "", "1", `"one"`}}))
+
+	report = Diff(JSON(map[string]int{"": 1}), JSON(map[string]int{"": 2}))
+	Expect(report.Body).To(Equal([]Difference{{DiffChanged, "/", "1", "2"}}))
+}
+
+func (s *DiffSuite) TestXML(t *testing.T) {
+	a := Respond([]byte(`<feed xmlns="urn:a"><entry id="1" lang="en"><title>One</title></entry><entry id="2"/></feed>`))
+	a.SetHeader("Content-Type", "application/xml")
+
+	b := Respond([]byte(`<?xml version="1.0"?>
+<feed xmlns="urn:a">
+  <entry lang="en" id="1">
+    <title> Uno </title>
+  </entry>
+</feed>`))
+	b.SetHeader("Content-Type", "application/atom+xml")
+
+	report := Diff(a, b, WithDiffIgnoredHeaders("Content-Type", "Content-Length"))
+	Expect(report.Body).To(Equal([]Difference{
+		{DiffChanged, "/{urn:a}feed/{urn:a}entry[1]/{urn:a}title[1]/text()", "One", "Uno"},
+		{DiffRemoved, "/{urn:a}feed/{urn:a}entry[2]", "{urn:a}entry", ""},
+	}))
+}
+
+func (s *DiffSuite) TestText(t *testing.T) {
+	a := Text("one\ntwo\nthree\nfour")
+	b := Text("one\n2\nthree\nfour\nfive")
+
+	report := Diff(a, b, WithDiffIgnoredHeaders("Content-Length"))
+	Expect(report.Body).To(Equal([]Difference{
+		{DiffChanged, "line 2", "two", "2"},
+		{DiffAdded, "line 5", "", "five"},
+	}))
+
+	report = Diff(Text("a\nb\nc"), Text("a\nc"), WithDiffIgnoredHeaders("Content-Length"))
+	Expect(report.Body).To(Equal([]Difference{{DiffRemoved, "line 2", "b", ""}}))
+}
+
+func (s *DiffSuite) TestMalformedJSONFallsBackToText(t *testing.T) {
+	a := Respond([]byte(`{"a": `)).SetHeader("Content-Type", "application/json")
+	b := Respond([]byte(`{"a": 1}`)).SetHeader("Content-Type", "application/json")
+
+	report := Diff(a, b, WithDiffIgnoredHeaders("Content-Length"))
+	Expect(report.Body).To(Equal([]Difference{{DiffChanged, "line 1", `{"a": `, `{"a": 1}`}}))
+}
+
+func (s *DiffSuite) TestBinary(t *testing.T) {
+	report := Diff(Respond([]byte{0xff, 0x01}), Respond([]byte{0xff}), WithDiffIgnoredHeaders("Content-Length"))
+	Expect(report.Body).To(Equal([]Difference{{DiffChanged, "bytes", "2 bytes", "1 bytes"}}))
+}
+
+func (s *DiffSuite) TestErrors(t *testing.T) {
+	report := Diff(failure(errors.New("utoh")), Empty(http.StatusInternalServerError))
+	Expect(report.Equal()).To(BeFalse())
+	Expect(report.ErrA).To(MatchError("utoh"))
+	Expect(report.String()).To(Equal("error writing a: utoh"))
+}
@@ -0,0 +1,232 @@
+package response
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// DigestAlgorithm is the name of a hash algorithm registered for use
+	// in the Content-Digest and Repr-Digest fields (see RFC 9530).
+	DigestAlgorithm string
+
+	digestConfig struct {
+		field      string
+		algorithms []DigestAlgorithm
+		request    *http.Request
+	}
+
+	// DigestConfigFunc is a function used to configure the Digest function.
+	DigestConfigFunc func(*digestConfig)
+)
+
+const (
+	// DigestSHA256 is the SHA-256 digest algorithm.
+	DigestSHA256 DigestAlgorithm = "sha-256"
+
+	// DigestSHA512 is the SHA-512 digest algorithm.
+	DigestSHA512 DigestAlgorithm = "sha-512"
+)
+
+// maxDigestPeek is the size of the largest streamed body which is read
+// ahead so that its digest can be sent as a header.
+const maxDigestPeek = 1 << 20
+
+// digestHashes maps supported digest algorithms to hash constructors.
+var digestHashes = map[DigestAlgorithm]func() hash.Hash{
+	DigestSHA256: sha256.New,
+	DigestSHA512: sha512.New,
+}
+
+// WithDigestAlgorithms sets the algorithms used when the client does not
+// state a preference. The default is SHA-256 only.
+func WithDigestAlgorithms(algorithms ...DigestAlgorithm) DigestConfigFunc {
+	return func(c *digestConfig) { c.algorithms = algorithms }
+}
+
+// WithDigestPreferences instructs Digest to honor the Want-Content-Digest
+// (or Want-Repr-Digest) header of the given request. The supported algorithm
+// with the highest preference is used. If the client does not list any
+// supported algorithm, the default algorithms it did not explicitly reject
+// are used instead.
+func WithDigestPreferences(r *http.Request) DigestConfigFunc {
+	return func(c *digestConfig) { c.request = r }
+}
+
+// WithReprDigest instructs Digest to send the Repr-Digest field instead
+// of the Content-Digest field.
+func WithReprDigest() DigestConfigFunc {
+	return func(c *digestConfig) { c.field = "Repr-Digest" }
+}
+
+// Digest computes an integrity digest of the response body as it is sent
+// to the client. Responses with a body which is known up-front (such as
+// those created by Respond and JSON) send the digest as a header, as do
+// streams with a Content-Length of at most 1MiB (such as those created by
+// File), whose body is read ahead. Other responses send the digest as a
+// trailer, which omits the Content-Length header (see SetTrailer). The
+// digest covers the bytes written by any writer decorators registered
+// before this call, so it should be applied last.
+func Digest(resp Response, configs ...DigestConfigFunc) Response {
+	config := &digestConfig{
+		field:      "Content-Digest",
+		algorithms: []DigestAlgorithm{DigestSHA256},
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	algorithms := supportedDigests(config.negotiate())
+	if len(algorithms) == 0 {
+		return resp
+	}
+
+	if r, ok := resp.(*response); ok {
+		if body, ok := peekBody(r); ok {
+			hashes := newDigestHashes(algorithms)
+			for _, h := range hashes {
+				h.Write(body)
+			}
+
+			return resp.SetHeader(config.field, formatDigests(algorithms, hashes))
+		}
+	}
+
+	hashes := newDigestHashes(algorithms)
+
+	resp.DecorateWriter(func(w io.Writer) io.Writer {
+		writers := []io.Writer{w}
+		for _, h := range hashes {
+			writers = append(writers, h)
+		}
+
+		return io.MultiWriter(writers...)
+	})
+
+	return resp.AddTrailerFunc(config.field, func(err error) string {
+		if err != nil {
+			return ""
+		}
+
+		return formatDigests(algorithms, hashes)
+	})
+}
+
+// peekBody returns the entire body of the response if it can be determined
+// before the response is written: the body is buffered, or it is a stream
+// with an undecorated writer and a Content-Length no larger than
+// maxDigestPeek, in which case the body is read ahead.
+func peekBody(r *response) ([]byte, bool) {
+	if r.buffered {
+		return r.body, true
+	}
+
+	if r.decorated || r.peek == nil {
+		return nil, false
+	}
+
+	length, err := strconv.ParseInt(r.header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 || length > maxDigestPeek {
+		return nil, false
+	}
+
+	data := r.peek(int(length))
+	return data, int64(len(data)) == length
+}
+
+// negotiate determines the digest algorithms to send.
+func (c *digestConfig) negotiate() []DigestAlgorithm {
+	if c.request == nil {
+		return c.algorithms
+	}
+
+	header := c.request.Header.Get("Want-" + c.field)
+	if header == "" {
+		return c.algorithms
+	}
+
+	type preference struct {
+		algorithm DigestAlgorithm
+		weight    int
+	}
+
+	var (
+		preferences = []preference{}
+		rejected    = map[DigestAlgorithm]bool{}
+	)
+
+	for _, member := range parseDictionary(header) {
+		algorithm := DigestAlgorithm(strings.ToLower(member.key))
+		if _, ok := digestHashes[algorithm]; !ok {
+			continue
+		}
+
+		weight, err := strconv.Atoi(member.value)
+		if err != nil || weight < 0 || weight > 10 {
+			continue
+		}
+
+		if weight == 0 {
+			rejected[algorithm] = true
+		} else {
+			preferences = append(preferences, preference{algorithm, weight})
+		}
+	}
+
+	if len(preferences) > 0 {
+		sort.SliceStable(preferences, func(i, j int) bool {
+			return preferences[i].weight > preferences[j].weight
+		})
+
+		return []DigestAlgorithm{preferences[0].algorithm}
+	}
+
+	algorithms := []DigestAlgorithm{}
+	for _, algorithm := range c.algorithms {
+		if !rejected[algorithm] {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+
+	return algorithms
+}
+
+// supportedDigests removes unsupported algorithms from the given list.
+func supportedDigests(algorithms []DigestAlgorithm) []DigestAlgorithm {
+	supported := []DigestAlgorithm{}
+	for _, algorithm := range algorithms {
+		if _, ok := digestHashes[algorithm]; ok {
+			supported = append(supported, algorithm)
+		}
+	}
+
+	return supported
+}
+
+// newDigestHashes creates a hash for each of the given algorithms.
+func newDigestHashes(algorithms []DigestAlgorithm) []hash.Hash {
+	hashes := []hash.Hash{}
+	for _, algorithm := range algorithms {
+		hashes = append(hashes, digestHashes[algorithm]())
+	}
+
+	return hashes
+}
+
+// formatDigests serializes the sums of the given hashes as a structured
+// field dictionary keyed by algorithm.
+func formatDigests(algorithms []DigestAlgorithm, hashes []hash.Hash) string {
+	members := []string{}
+	for i, h := range hashes {
+		members = append(members, string(algorithms[i])+"="+formatByteSequence(h.Sum(nil)))
+	}
+
+	return strings.Join(members, ", ")
+}
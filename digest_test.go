@@ -0,0 +1,145 @@
+package response
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type DigestSuite struct{}
+
+func (s *DigestSuite) TestDigestHeader(t *testing.T) {
+	resp := Digest(Respond([]byte("hello world")))
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Digest")).To(Equal("sha-256=" + sha256Digest("hello world")))
+	Expect(headers.Get("Trailer")).To(BeEmpty())
+}
+
+func (s *DigestSuite) TestDigestJSON(t *testing.T) {
+	resp := Digest(JSON(map[string]int{"foo": 1}), WithReprDigest())
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Repr-Digest")).To(Equal("sha-256=" + sha256Digest(`{"foo":1}`)))
+}
+
+func (s *DigestSuite) TestDigestMultipleAlgorithms(t *testing.T) {
+	resp := Digest(Respond([]byte("hello world")), WithDigestAlgorithms(DigestSHA512, DigestSHA256, "md5"))
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Digest")).To(Equal(
+		"sha-512=" + sha512Digest("hello world") + ", " +
+			"sha-256=" + sha256Digest("hello world"),
+	))
+}
+
+func (s *DigestSuite) TestDigestTrailer(t *testing.T) {
+	data := makeData()
+	resp := Digest(Stream(ioutil.NopCloser(bytes.NewReader(data))))
+
+	headers, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(body).To(Equal(data))
+	Expect(headers.Get("Content-Digest")).To(BeEmpty())
+	Expect(headers.Get("Trailer")).To(Equal("Content-Digest"))
+	Expect(headers.Get(http.TrailerPrefix + "Content-Digest")).To(Equal("sha-256=" + sha256Digest(string(data))))
+}
+
+func (s *DigestSuite) TestDigestStreamLength(t *testing.T) {
+	data := makeData()
+	resp := Stream(ioutil.NopCloser(bytes.NewReader(data)))
+	resp.SetHeader("Content-Length", "262144")
+
+	headers, body, err := Serialize(Digest(resp))
+	Expect(err).To(BeNil())
+	Expect(body).To(Equal(data))
+	Expect(headers.Get("Content-Length")).To(Equal("262144"))
+	Expect(headers.Get("Content-Digest")).To(Equal("sha-256=" + sha256Digest(string(data))))
+	Expect(headers.Get("Trailer")).To(BeEmpty())
+}
+
+func (s *DigestSuite) TestDigestStreamLengthTooLarge(t *testing.T) {
+	data := bytes.Repeat(makeData(), 5)
+	resp := Stream(ioutil.NopCloser(bytes.NewReader(data)))
+	resp.SetHeader("Content-Length", "1310720")
+
+	headers, body, err := Serialize(Digest(resp))
+	Expect(err).To(BeNil())
+	Expect(body).To(Equal(data))
+	Expect(headers.Get("Content-Length")).To(BeEmpty())
+	Expect(headers.Get(http.TrailerPrefix + "Content-Digest")).To(Equal("sha-256=" + sha256Digest(string(data))))
+}
+
+func (s *DigestSuite) TestDigestFile(t *testing.T) {
+	resp := Dir(testFiles())(httptest.NewRequest("GET", "/css/site.css", nil))
+
+	headers, body, err := Serialize(Digest(Sniff(resp)))
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("body { color: red; }"))
+	Expect(headers.Get("Content-Length")).To(Equal("20"))
+	Expect(headers.Get("Content-Digest")).To(Equal("sha-256=" + sha256Digest("body { color: red; }")))
+}
+
+func (s *DigestSuite) TestDigestAfterDecorator(t *testing.T) {
+	resp := Respond([]byte("abc"))
+	resp.DecorateWriter(func(w io.Writer) io.Writer {
+		return WriterFunc(func(p []byte) (int, error) { return w.Write(upperBytes(p)) })
+	})
+
+	headers, _, err := Serialize(Digest(resp))
+	Expect(err).To(BeNil())
+	Expect(headers.Get(http.TrailerPrefix + "Content-Digest")).To(Equal("sha-256=" + sha256Digest("ABC")))
+}
+
+func (s *DigestSuite) TestDigestPreferences(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Want-Content-Digest", "sha-256=3, sha-512=10, md5=10")
+
+	headers, _, err := Serialize(Digest(Respond([]byte("hello world")), WithDigestPreferences(r)))
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Digest")).To(Equal("sha-512=" + sha512Digest("hello world")))
+}
+
+func (s *DigestSuite) TestDigestPreferencesRejected(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Want-Content-Digest", "sha-256=0, md5=10")
+
+	resp := Digest(Respond([]byte("hello world")), WithDigestPreferences(r), WithDigestAlgorithms(DigestSHA256))
+	Expect(resp.Header("Content-Digest")).To(BeEmpty())
+
+	resp = Digest(Respond([]byte("hello world")), WithDigestPreferences(r), WithDigestAlgorithms(DigestSHA256, DigestSHA512))
+	Expect(resp.Header("Content-Digest")).To(Equal("sha-512=" + sha512Digest("hello world")))
+}
+
+func (s *DigestSuite) TestDigestReprPreferences(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Want-Content-Digest", "sha-512=10")
+	r.Header.Set("Want-Repr-Digest", "sha-256=10")
+
+	resp := Digest(Respond([]byte("hello world")), WithDigestPreferences(r), WithReprDigest())
+	Expect(resp.Header("Repr-Digest")).To(Equal("sha-256=" + sha256Digest("hello world")))
+}
+
+//
+//
+
+func sha256Digest(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return ":" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+func sha512Digest(data string) string {
+	sum := sha512.Sum512([]byte(data))
+	return ":" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
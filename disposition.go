@@ -0,0 +1,99 @@
+package response
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Attachment sets the Content-Disposition header of the response so that
+// clients save the body as a file with the given name (see RFC 6266).
+func Attachment(resp Response, filename string) Response {
+	return resp.SetHeader("Content-Disposition", ContentDisposition("attachment", filename))
+}
+
+// Inline sets the Content-Disposition header of the response so that clients
+// display the body. The filename is used if the user saves the content. An
+// empty filename is omitted.
+func Inline(resp Response, filename string) Response {
+	return resp.SetHeader("Content-Disposition", ContentDisposition("inline", filename))
+}
+
+// ContentDisposition serializes a Content-Disposition value with the given
+// type and filename. Directory components and control characters are removed
+// from the filename. Filenames which cannot be represented as a plain ASCII
+// quoted string are also sent in the filename* parameter encoded as UTF-8
+// (see RFC 5987), along with an ASCII approximation for older clients.
+func ContentDisposition(dispositionType, filename string) string {
+	filename = sanitizeFilename(filename)
+	if filename == "" {
+		return dispositionType
+	}
+
+	fallback := asciiFilename(filename)
+	if fallback == filename {
+		return fmt.Sprintf(`%s; filename="%s"`, dispositionType, fallback)
+	}
+
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, dispositionType, fallback, encodeExtValue(filename))
+}
+
+// sanitizeFilename removes everything up to the last path separator as well
+// as control characters and invalid UTF-8 from the filename.
+func sanitizeFilename(filename string) string {
+	if i := strings.LastIndexAny(filename, `/\`); i >= 0 {
+		filename = filename[i+1:]
+	}
+
+	filename = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == utf8.RuneError || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+
+		return r
+	}, filename)
+
+	filename = strings.TrimSpace(filename)
+	if filename == "." || filename == ".." {
+		return ""
+	}
+
+	return filename
+}
+
+// asciiFilename replaces characters which are not safe within a quoted
+// filename parameter with an underscore.
+func asciiFilename(filename string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 0x80 || r == '"' || r == '%' {
+			return '_'
+		}
+
+		return r
+	}, filename)
+}
+
+// encodeExtValue percent-encodes all characters of the value which are not
+// attr-chars (see RFC 5987, section 3.2.1).
+func encodeExtValue(value string) string {
+	encoded := &strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; isAttrChar(c) {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(encoded, "%%%02X", c)
+		}
+	}
+
+	return encoded.String()
+}
+
+// isAttrChar returns true if the character may appear unencoded in an
+// extended parameter value.
+func isAttrChar(c byte) bool {
+	if c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+		return true
+	}
+
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,44 @@
+package response
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type DispositionSuite struct{}
+
+func (s *DispositionSuite) TestContentDisposition(t *testing.T) {
+	for filename, expected := range map[string]string{
+		"report.pdf":            `attachment; filename="report.pdf"`,
+		"my report (1).pdf":     `attachment; filename="my report (1).pdf"`,
+		"résumé.pdf":            `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`,
+		"日本語.txt":               `attachment; filename="___.txt"; filename*=UTF-8''%E6%97%A5%E6%9C%AC%E8%AA%9E.txt`,
+		`say "hi".txt`:          `attachment; filename="say _hi_.txt"; filename*=UTF-8''say%20%22hi%22.txt`,
+		"100%.txt":              `attachment; filename="100_.txt"; filename*=UTF-8''100%25.txt`,
+		"../../etc/passwd":      `attachment; filename="passwd"`,
+		`C:\Users\bob\file.txt`: `attachment; filename="file.txt"`,
+		"bad\r\nname.txt":       `attachment; filename="badname.txt"`,
+		"  spaced.txt ":         `attachment; filename="spaced.txt"`,
+		"dir/..":                `attachment`,
+		"":                      `attachment`,
+	} {
+		Expect(ContentDisposition("attachment", filename)).To(Equal(expected), filename)
+	}
+}
+
+func (s *DispositionSuite) TestAttachment(t *testing.T) {
+	resp := Attachment(Stream(ioutil.NopCloser(bytes.NewReader([]byte("data")))), "données.csv")
+
+	headers, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(body).To(Equal([]byte("data")))
+	Expect(headers.Get("Content-Disposition")).To(Equal(`attachment; filename="donn_es.csv"; filename*=UTF-8''donn%C3%A9es.csv`))
+}
+
+func (s *DispositionSuite) TestInline(t *testing.T) {
+	Expect(Inline(Respond([]byte("data")), "image.png").Header("Content-Disposition")).To(Equal(`inline; filename="image.png"`))
+	Expect(Inline(Respond([]byte("data")), "").Header("Content-Disposition")).To(Equal(`inline`))
+}
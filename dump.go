@@ -0,0 +1,119 @@
+package response
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Dump writes the response and returns the HTTP/1.1 message which would be
+// sent to the client: the status line, the headers in order of their names,
+// and the body. The body is sent with chunked transfer encoding (followed by
+// the trailers) if the response has trailers or lacks a Content-Length
+// header. Otherwise, the body is sent as-is. An error is returned if writing
+// the response fails.
+func Dump(resp Response) ([]byte, error) {
+	statusCode := resp.StatusCode()
+
+	serialized, body, err := Serialize(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	header, trailer := http.Header{}, http.Header{}
+	for k, vs := range serialized {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			trailer[strings.TrimPrefix(k, http.TrailerPrefix)] = vs
+		} else {
+			header[k] = vs
+		}
+	}
+
+	chunked := false
+	if bodyAllowed(statusCode) {
+		if len(trailer) > 0 || header.Get("Content-Length") == "" {
+			chunked = len(body) > 0 || len(trailer) > 0
+		}
+
+		if chunked {
+			header.Del("Content-Length")
+			header.Set("Transfer-Encoding", "chunked")
+		} else if header.Get("Content-Length") == "" {
+			header.Set("Content-Length", "0")
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+
+	text := http.StatusText(statusCode)
+	if text == "" {
+		text = fmt.Sprintf("status code %d", statusCode)
+	}
+
+	fmt.Fprintf(buffer, "HTTP/1.1 %03d %s\r\n", statusCode, text)
+	if err := header.Write(buffer); err != nil {
+		return nil, err
+	}
+
+	buffer.WriteString("\r\n")
+
+	if !chunked {
+		buffer.Write(body)
+		return buffer.Bytes(), nil
+	}
+
+	if len(body) > 0 {
+		fmt.Fprintf(buffer, "%x\r\n", len(body))
+		buffer.Write(body)
+		buffer.WriteString("\r\n")
+	}
+
+	buffer.WriteString("0\r\n")
+	if err := trailer.Write(buffer); err != nil {
+		return nil, err
+	}
+
+	buffer.WriteString("\r\n")
+	return buffer.Bytes(), nil
+}
+
+// Parse reads an HTTP/1.1 response message (such as one produced by Dump)
+// and creates a buffered response with the same status code, headers, body,
+// and trailers. A response with a chunked body is created without a
+// Content-Length header.
+func Parse(r io.Reader) (Response, error) {
+	parsed, err := http.ReadResponse(bufio.NewReader(r), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer parsed.Body.Close()
+
+	body, err := io.ReadAll(parsed.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := parsed.Header.Clone()
+	for k, vs := range parsed.Trailer {
+		if len(vs) > 0 {
+			header[http.TrailerPrefix+k] = vs
+		}
+	}
+
+	resp := Reconstruct(parsed.StatusCode, header, body)
+	if len(parsed.Header["Content-Length"]) == 0 {
+		resp.SetHeader("Content-Length", "")
+	}
+
+	return resp, nil
+}
+
+// bodyAllowed returns true if a response with the given status code may
+// include a body.
+func bodyAllowed(statusCode int) bool {
+	return statusCode >= 200 && statusCode != http.StatusNoContent && statusCode != http.StatusNotModified
+}
@@ -0,0 +1,171 @@
+package response
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type DumpSuite struct{}
+
+func (s *DumpSuite) TestLengthDelimited(t *testing.T) {
+	resp := Respond([]byte("hello"))
+	resp.SetStatusCode(http.StatusCreated)
+	resp.SetHeader("X-B", "2")
+	resp.AddHeader("X-A", "1")
+	resp.AddHeader("X-A", "3")
+
+	dump, err := Dump(resp)
+	Expect(err).To(BeNil())
+	Expect(string(dump)).To(Equal("" +
+		"HTTP/1.1 201 Created\r\n" +
+		"Content-Length: 5\r\n" +
+		"X-A: 1\r\n" +
+		"X-A: 3\r\n" +
+		"X-B: 2\r\n" +
+		"\r\n" +
+		"hello",
+	))
+}
+
+func (s *DumpSuite) TestChunked(t *testing.T) {
+	resp := Stream(io.NopCloser(strings.NewReader("hello world")))
+	resp.SetHeader("Content-Type", "text/plain")
+
+	dump, err := Dump(resp)
+	Expect(err).To(BeNil())
+	Expect(string(dump)).To(Equal("" +
+		"HTTP/1.1 200 OK\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"Transfer-Encoding: chunked\r\n" +
+		"\r\n" +
+		"b\r\n" +
+		"hello world\r\n" +
+		"0\r\n" +
+		"\r\n",
+	))
+}
+
+func (s *DumpSuite) TestTrailers(t *testing.T) {
+	resp := Respond([]byte("hello"))
+	resp.SetTrailer("X-Checksum", "abc")
+	resp.AddTrailerFunc("X-Status", func(err error) string { return "ok" })
+
+	dump, err := Dump(resp)
+	Expect(err).To(BeNil())
+	Expect(string(dump)).To(Equal("" +
+		"HTTP/1.1 200 OK\r\n" +
+		"Trailer: X-Checksum\r\n" +
+		"Trailer: X-Status\r\n" +
+		"Transfer-Encoding: chunked\r\n" +
+		"\r\n" +
+		"5\r\n" +
+		"hello\r\n" +
+		"0\r\n" +
+		"X-Checksum: abc\r\n" +
+		"X-Status: ok\r\n" +
+		"\r\n",
+	))
+}
+
+func (s *DumpSuite) TestNoBody(t *testing.T) {
+	dump, err := Dump(Empty(http.StatusNoContent).SetHeader("Content-Length", ""))
+	Expect(err).To(BeNil())
+	Expect(string(dump)).To(Equal("HTTP/1.1 204 No Content\r\n\r\n"))
+
+	dump, err = Dump(Stream(io.NopCloser(strings.NewReader(""))))
+	Expect(err).To(BeNil())
+	Expect(string(dump)).To(Equal("HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n"))
+}
+
+func (s *DumpSuite) TestUnknownStatus(t *testing.T) {
+	dump, err := Dump(Empty(599))
+	Expect(err).To(BeNil())
+	Expect(string(dump)).To(HavePrefix("HTTP/1.1 599 status code 599\r\n"))
+}
+
+func (s *DumpSuite) TestError(t *testing.T) {
+	resp := Stream(io.NopCloser(&errorReader{errors.New("utoh")}))
+
+	_, err := Dump(resp)
+	Expect(err).To(MatchError("utoh"))
+}
+
+func (s *DumpSuite) TestParse(t *testing.T) {
+	resp, err := Parse(strings.NewReader("" +
+		"HTTP/1.1 201 Created\r\n" +
+		"Content-Length: 5\r\n" +
+		"X-A: 1\r\n" +
+		"X-A: 3\r\n" +
+		"\r\n" +
+		"hello",
+	))
+
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode()).To(Equal(http.StatusCreated))
+	Expect(resp.Header("Content-Length")).To(Equal("5"))
+
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+	Expect(w.Header()["X-A"]).To(Equal([]string{"1", "3"}))
+	Expect(w.Body.String()).To(Equal("hello"))
+}
+
+func (s *DumpSuite) TestParseChunked(t *testing.T) {
+	resp, err := Parse(strings.NewReader("" +
+		"HTTP/1.1 200 OK\r\n" +
+		"Trailer: X-Checksum\r\n" +
+		"Transfer-Encoding: chunked\r\n" +
+		"\r\n" +
+		"5\r\n" +
+		"hello\r\n" +
+		"6\r\n" +
+		" world\r\n" +
+		"0\r\n" +
+		"X-Checksum: abc\r\n" +
+		"\r\n",
+	))
+
+	Expect(err).To(BeNil())
+	Expect(resp.Header("Content-Length")).To(BeEmpty())
+	Expect(resp.Header("Transfer-Encoding")).To(BeEmpty())
+	Expect(resp.Trailer("X-Checksum")).To(Equal("abc"))
+
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+	Expect(w.Body.String()).To(Equal("hello world"))
+	Expect(w.Result().Trailer.Get("X-Checksum")).To(Equal("abc"))
+}
+
+func (s *DumpSuite) TestParseMalformed(t *testing.T) {
+	_, err := Parse(strings.NewReader("HTTP/1.1 OK\r\n\r\n"))
+	Expect(err).To(HaveOccurred())
+
+	_, err = Parse(strings.NewReader("HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\nzz\r\n"))
+	Expect(err).To(HaveOccurred())
+}
+
+func (s *DumpSuite) TestRoundTrip(t *testing.T) {
+	for _, resp := range []Response{
+		JSON(map[string]int{"a": 1}).SetHeader("X-Request-Id", "1"),
+		Respond([]byte("hello")).SetTrailer("X-Checksum", "abc"),
+		Stream(io.NopCloser(strings.NewReader("streamed"))),
+		Empty(http.StatusNotModified).SetHeader("Content-Length", ""),
+	} {
+		dump, err := Dump(resp)
+		Expect(err).To(BeNil())
+
+		parsed, err := Parse(bytes.NewReader(dump))
+		Expect(err).To(BeNil())
+
+		redump, err := Dump(parsed)
+		Expect(err).To(BeNil())
+		Expect(string(redump)).To(Equal(string(dump)))
+	}
+}
@@ -0,0 +1,377 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Fault decorates a response so that it misbehaves when written.
+	Fault func(Response) Response
+
+	faultConfig struct {
+		header string
+		seed   int64
+		rules  []faultRule
+	}
+
+	// faultRule applies a set of faults to a random subset of requests.
+	faultRule struct {
+		probability float64
+		faults      []Fault
+	}
+
+	// FaultConfigFunc is a function used to configure the InjectFaults
+	// middleware.
+	FaultConfigFunc func(*faultConfig)
+
+	// faultWriter is a misbehaving writer which forwards flushes to the
+	// writer it decorates so that partially written bodies are observable.
+	faultWriter struct {
+		WriterFunc
+		target io.Writer
+	}
+)
+
+// ErrInjectedFault is the error returned from writes that fail due to an
+// injected fault.
+var ErrInjectedFault = errors.New("injected fault")
+
+// Latency delays the status line and headers of the response by the given
+// duration.
+func Latency(d time.Duration) Fault {
+	return func(resp Response) Response {
+		r, ok := resp.(*response)
+		if !ok {
+			return resp
+		}
+
+		r.beforeHeader = append(r.beforeHeader, func() { time.Sleep(d) })
+		return r
+	}
+}
+
+// Throttle limits the rate at which the body of the response is written to
+// the given number of bytes per second. The body is flushed in chunks of
+// roughly a tenth of that size. A non-positive rate has no effect.
+func Throttle(bytesPerSecond int) Fault {
+	if bytesPerSecond <= 0 {
+		return func(resp Response) Response { return resp }
+	}
+
+	chunkSize := bytesPerSecond / 10
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+
+	return decorateFault(func(w io.Writer) io.Writer {
+		return &faultWriter{target: w, WriterFunc: func(p []byte) (int, error) {
+			written := 0
+			for len(p) > 0 {
+				chunk := p
+				if len(chunk) > chunkSize {
+					chunk = chunk[:chunkSize]
+				}
+
+				n, err := w.Write(chunk)
+				written += n
+				if err != nil {
+					return written, err
+				}
+
+				flush(w)
+				time.Sleep(time.Duration(n) * time.Second / time.Duration(bytesPerSecond))
+				p = p[n:]
+			}
+
+			return written, nil
+		}}
+	})
+}
+
+// Truncate silently discards the body of the response after the given number
+// of bytes. The handler observes successful writes, but the client receives
+// an incomplete body.
+func Truncate(n int64) Fault {
+	return decorateFault(func(w io.Writer) io.Writer {
+		remaining := n
+
+		return &faultWriter{target: w, WriterFunc: func(p []byte) (int, error) {
+			if remaining <= 0 {
+				return len(p), nil
+			}
+
+			data := p
+			if int64(len(data)) > remaining {
+				data = data[:remaining]
+			}
+
+			written, err := w.Write(data)
+			remaining -= int64(written)
+			if err != nil {
+				return written, err
+			}
+
+			return len(p), nil
+		}}
+	})
+}
+
+// WriteError causes writes of the body of the response to fail with the
+// given error (or ErrInjectedFault if nil) after the given number of bytes.
+func WriteError(after int64, err error) Fault {
+	if err == nil {
+		err = ErrInjectedFault
+	}
+
+	return decorateFault(func(w io.Writer) io.Writer {
+		remaining := after
+
+		return &faultWriter{target: w, WriterFunc: func(p []byte) (int, error) {
+			if int64(len(p)) <= remaining {
+				n, writeErr := w.Write(p)
+				remaining -= int64(n)
+				return n, writeErr
+			}
+
+			n, writeErr := w.Write(p[:remaining])
+			remaining -= int64(n)
+			if writeErr != nil {
+				return n, writeErr
+			}
+
+			return n, err
+		}}
+	})
+}
+
+// Corrupt flips a random bit of each byte of the body of the response with
+// the given probability. The corrupted bytes are determined by the seed.
+func Corrupt(probability float64, seed int64) Fault {
+	return decorateFault(func(w io.Writer) io.Writer {
+		random := rand.New(rand.NewSource(seed))
+
+		return &faultWriter{target: w, WriterFunc: func(p []byte) (int, error) {
+			data := make([]byte, len(p))
+			copy(data, p)
+
+			for i := range data {
+				if random.Float64() < probability {
+					data[i] ^= 1 << uint(random.Intn(8))
+				}
+			}
+
+			return w.Write(data)
+		}}
+	})
+}
+
+// Drop aborts the connection after the given number of bytes of the body
+// of the response has been written and flushed. The connection is aborted
+// by panicking with http.ErrAbortHandler, which the server recovers from
+// silently. Callbacks of the response are not invoked.
+func Drop(after int64) Fault {
+	return decorateFault(func(w io.Writer) io.Writer {
+		remaining := after
+
+		return &faultWriter{target: w, WriterFunc: func(p []byte) (int, error) {
+			if int64(len(p)) <= remaining {
+				n, err := w.Write(p)
+				remaining -= int64(n)
+				return n, err
+			}
+
+			if _, err := w.Write(p[:remaining]); err != nil {
+				return 0, err
+			}
+
+			flush(w)
+			panic(http.ErrAbortHandler)
+		}}
+	})
+}
+
+// ParseFaults parses a comma-separated list of faults of the form name=value.
+// The supported faults are latency (a duration), throttle (bytes per second),
+// truncate, error, and drop (a byte offset), and corrupt (a probability with
+// an optional seed separated by @, e.g. corrupt=0.01@42).
+func ParseFaults(spec string) ([]Fault, error) {
+	faults := []Fault{}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed fault %q", part)
+		}
+
+		fault, err := parseFault(strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("malformed fault %q (%s)", part, err)
+		}
+
+		faults = append(faults, fault)
+	}
+
+	return faults, nil
+}
+
+// parseFault creates the fault with the given name and value.
+func parseFault(name, value string) (Fault, error) {
+	switch name {
+	case "latency":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid duration")
+		}
+
+		return Latency(d), nil
+
+	case "throttle":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid rate")
+		}
+
+		return Throttle(n), nil
+
+	case "truncate", "error", "drop":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid offset")
+		}
+
+		switch name {
+		case "truncate":
+			return Truncate(n), nil
+		case "error":
+			return WriteError(n, nil), nil
+		default:
+			return Drop(n), nil
+		}
+
+	case "corrupt":
+		value, rawSeed, _ := strings.Cut(value, "@")
+
+		probability, err := strconv.ParseFloat(value, 64)
+		if err != nil || probability < 0 || probability > 1 {
+			return nil, fmt.Errorf("invalid probability")
+		}
+
+		var seed int64
+		if rawSeed != "" {
+			if seed, err = strconv.ParseInt(rawSeed, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid seed")
+			}
+		}
+
+		return Corrupt(probability, seed), nil
+	}
+
+	return nil, fmt.Errorf("unknown fault")
+}
+
+// WithFaultHeader enables faults specified by the client in the request
+// header with the given name (see ParseFaults). A request with a malformed
+// specification is rejected with a 400 problem response. This should only
+// be enabled in test environments.
+func WithFaultHeader(name string) FaultConfigFunc {
+	return func(c *faultConfig) { c.header = name }
+}
+
+// WithFaultProbability applies the given faults to a random subset of the
+// requests with the given probability. Each rule is evaluated independently.
+func WithFaultProbability(probability float64, faults ...Fault) FaultConfigFunc {
+	return func(c *faultConfig) { c.rules = append(c.rules, faultRule{probability, faults}) }
+}
+
+// WithFaultSeed sets the seed of the random source which determines the
+// requests to which faults are applied. For a given seed, the sequence of
+// faulted requests is deterministic. The default seed is 1.
+func WithFaultSeed(seed int64) FaultConfigFunc {
+	return func(c *faultConfig) { c.seed = seed }
+}
+
+// InjectFaults creates middleware which applies faults to the responses of
+// the wrapped handler, either as requested via the configured header or as
+// scheduled by the configured probabilities. Faults are applied in order,
+// so that the writer of the last fault is closest to the client.
+func InjectFaults(configs ...FaultConfigFunc) Middleware {
+	config := &faultConfig{seed: 1}
+	for _, f := range configs {
+		f(config)
+	}
+
+	var (
+		mutex  sync.Mutex
+		random = rand.New(rand.NewSource(config.seed))
+	)
+
+	schedule := func() []Fault {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		faults := []Fault{}
+		for _, rule := range config.rules {
+			if random.Float64() < rule.probability {
+				faults = append(faults, rule.faults...)
+			}
+		}
+
+		return faults
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(r *http.Request) Response {
+			faults := schedule()
+
+			if config.header != "" {
+				if spec := r.Header.Get(config.header); spec != "" {
+					requested, err := ParseFaults(spec)
+					if err != nil {
+						return ProblemJSON(NewProblem(http.StatusBadRequest, "%s", err))
+					}
+
+					faults = append(faults, requested...)
+				}
+			}
+
+			resp := next(r)
+			for _, f := range faults {
+				resp = f(resp)
+			}
+
+			return resp
+		}
+	}
+}
+
+// decorateFault creates a fault which decorates the writer of the response.
+func decorateFault(f WriterDecorator) Fault {
+	return func(resp Response) Response {
+		return resp.DecorateWriter(f)
+	}
+}
+
+// Flush flushes the decorated writer if it is a flusher.
+func (w *faultWriter) Flush() {
+	flush(w.target)
+}
+
+// flush flushes the given writer if it is a flusher.
+func flush(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
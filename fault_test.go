@@ -0,0 +1,206 @@
+package response
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type FaultSuite struct{}
+
+func (s *FaultSuite) TestLatency(t *testing.T) {
+	resp := Latency(50 * time.Millisecond)(Respond([]byte("hello")))
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+
+	Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+	Expect(w.Body.String()).To(Equal("hello"))
+}
+
+func (s *FaultSuite) TestThrottle(t *testing.T) {
+	resp := Throttle(100)(Stream(io.NopCloser(strings.NewReader(strings.Repeat("x", 20)))))
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+
+	Expect(time.Since(start)).To(BeNumerically(">=", 200*time.Millisecond))
+	Expect(w.Body.String()).To(Equal(strings.Repeat("x", 20)))
+	Expect(w.Flushed).To(BeTrue())
+}
+
+func (s *FaultSuite) TestTruncate(t *testing.T) {
+	var err error
+	resp := Truncate(5)(Respond([]byte("hello world")))
+	resp.AddCallback(func(e error) { err = e })
+
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+
+	Expect(err).To(BeNil())
+	Expect(w.Body.String()).To(Equal("hello"))
+	Expect(w.Header().Get("Content-Length")).To(Equal("11"))
+}
+
+func (s *FaultSuite) TestWriteError(t *testing.T) {
+	var err error
+	resp := WriteError(5, nil)(Respond([]byte("hello world")))
+	resp.AddCallback(func(e error) { err = e })
+
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+
+	Expect(err).To(Equal(ErrInjectedFault))
+	Expect(w.Body.String()).To(Equal("hello"))
+}
+
+func (s *FaultSuite) TestWriteErrorCustom(t *testing.T) {
+	var (
+		err      error
+		expected = errors.New("utoh")
+	)
+
+	resp := WriteError(0, expected)(Respond([]byte("hello world")))
+	resp.AddCallback(func(e error) { err = e })
+	resp.WriteTo(httptest.NewRecorder())
+
+	Expect(err).To(Equal(expected))
+}
+
+func (s *FaultSuite) TestCorrupt(t *testing.T) {
+	body := bytes.Repeat([]byte("abcdefgh"), 64)
+
+	serialize := func(f Fault) []byte {
+		w := httptest.NewRecorder()
+		f(Respond(body)).WriteTo(w)
+		return w.Body.Bytes()
+	}
+
+	corrupted := serialize(Corrupt(0.1, 42))
+	Expect(corrupted).To(HaveLen(len(body)))
+	Expect(corrupted).NotTo(Equal(body))
+	Expect(serialize(Corrupt(0.1, 42))).To(Equal(corrupted))
+	Expect(serialize(Corrupt(0.1, 43))).NotTo(Equal(corrupted))
+	Expect(serialize(Corrupt(0, 42))).To(Equal(body))
+	Expect(body).To(Equal(bytes.Repeat([]byte("abcdefgh"), 64)))
+}
+
+func (s *FaultSuite) TestDrop(t *testing.T) {
+	w := httptest.NewRecorder()
+	resp := Drop(5)(Respond([]byte("hello world")))
+
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		resp.WriteTo(w)
+	}()
+
+	Expect(recovered).To(Equal(http.ErrAbortHandler))
+	Expect(w.Body.String()).To(Equal("hello"))
+	Expect(w.Flushed).To(BeTrue())
+}
+
+func (s *FaultSuite) TestParseFaults(t *testing.T) {
+	faults, err := ParseFaults("latency=10ms, throttle=1024, truncate=10, error=5, corrupt=0.01@42, drop=3")
+	Expect(err).To(BeNil())
+	Expect(faults).To(HaveLen(6))
+
+	faults, err = ParseFaults("")
+	Expect(err).To(BeNil())
+	Expect(faults).To(BeEmpty())
+}
+
+func (s *FaultSuite) TestParseFaultsMalformed(t *testing.T) {
+	for _, spec := range []string{
+		"latency",
+		"latency=soon",
+		"throttle=0",
+		"truncate=-1",
+		"corrupt=2",
+		"corrupt=0.5@x",
+		"explode=1",
+	} {
+		_, err := ParseFaults(spec)
+		Expect(err).To(HaveOccurred(), spec)
+	}
+}
+
+func (s *FaultSuite) TestInjectFaultsHeader(t *testing.T) {
+	handler := InjectFaults(WithFaultHeader("X-Fault"))(func(r *http.Request) Response {
+		return Respond([]byte("hello world"))
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Fault", "truncate=5")
+
+	w := httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, r)
+	Expect(w.Body.String()).To(Equal("hello"))
+
+	w = httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	Expect(w.Body.String()).To(Equal("hello world"))
+}
+
+func (s *FaultSuite) TestInjectFaultsHeaderDisabled(t *testing.T) {
+	handler := InjectFaults()(func(r *http.Request) Response {
+		return Respond([]byte("hello world"))
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Fault", "truncate=5")
+
+	w := httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, r)
+	Expect(w.Body.String()).To(Equal("hello world"))
+}
+
+func (s *FaultSuite) TestInjectFaultsHeaderMalformed(t *testing.T) {
+	handler := InjectFaults(WithFaultHeader("X-Fault"))(func(r *http.Request) Response {
+		return Respond([]byte("hello world"))
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Fault", "explode=1")
+
+	w := httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, r)
+	Expect(w.Code).To(Equal(http.StatusBadRequest))
+	Expect(w.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+	Expect(w.Body.String()).To(ContainSubstring(`malformed fault \"explode=1\" (unknown fault)`))
+}
+
+func (s *FaultSuite) TestInjectFaultsSchedule(t *testing.T) {
+	run := func(seed int64) []bool {
+		handler := InjectFaults(
+			WithFaultSeed(seed),
+			WithFaultProbability(0.5, Truncate(0)),
+		)(func(r *http.Request) Response {
+			return Respond([]byte("hello"))
+		})
+
+		faulted := []bool{}
+		for i := 0; i < 32; i++ {
+			w := httptest.NewRecorder()
+			Convert(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			faulted = append(faulted, w.Body.Len() == 0)
+		}
+
+		return faulted
+	}
+
+	schedule := run(7)
+	Expect(schedule).To(ContainElement(true))
+	Expect(schedule).To(ContainElement(false))
+	Expect(run(7)).To(Equal(schedule))
+	Expect(run(8)).NotTo(Equal(schedule))
+}
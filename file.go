@@ -0,0 +1,563 @@
+package response
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	dirConfig struct {
+		index         string
+		listing       bool
+		precompressed []string
+	}
+
+	// DirConfigFunc is a function used to configure the File and Dir
+	// functions.
+	DirConfigFunc func(*dirConfig)
+
+	// fileContent is the selected representation of a file.
+	fileContent struct {
+		file     fs.File
+		reader   io.ReadSeeker
+		size     int64
+		modTime  time.Time
+		etag     string
+		encoding string
+	}
+)
+
+// precompressedExtensions maps content codings to sidecar file extensions.
+var precompressedExtensions = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// WithIndexFile sets the name of the file served in place of a directory.
+// The default index file is index.html. An empty name disables index files.
+func WithIndexFile(name string) DirConfigFunc {
+	return func(c *dirConfig) { c.index = name }
+}
+
+// WithDirectoryListing instructs the handler to render an HTML list of the
+// entries of directories which do not contain an index file. Otherwise,
+// these directories are not found.
+func WithDirectoryListing() DirConfigFunc {
+	return func(c *dirConfig) { c.listing = true }
+}
+
+// WithPrecompressed sets the content codings (br and gzip) in order of
+// preference for which precompressed sidecar files (such as app.js.br and
+// app.js.gz) are served to clients which accept them. Both are enabled by
+// default. Calling this function without any codings disables sidecars.
+func WithPrecompressed(encodings ...string) DirConfigFunc {
+	return func(c *dirConfig) { c.precompressed = encodings }
+}
+
+// File creates a response containing the file at the given path of the
+// local file system. The response honors conditional and range requests.
+func File(r *http.Request, name string, configs ...DirConfigFunc) Response {
+	dir, base := filepath.Split(filepath.Clean(name))
+	if dir == "" {
+		dir = "."
+	}
+
+	return serveFile(r, os.DirFS(dir), base, newDirConfig(configs))
+}
+
+// Dir creates a handler which serves files from the given file system (such
+// as an embed.FS or the result of os.DirFS) at the path of the request URL.
+// Use http.StripPrefix to serve the file system under a path prefix. Paths
+// which contain .. segments are rejected.
+func Dir(fsys fs.FS, configs ...DirConfigFunc) HandlerFunc {
+	config := newDirConfig(configs)
+
+	return func(r *http.Request) Response {
+		if containsDotDot(r.URL.Path) {
+			return Text("invalid URL path").SetStatusCode(http.StatusBadRequest)
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" {
+			name = "."
+		}
+
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return fileError(err)
+		}
+
+		if !info.IsDir() {
+			return serveFile(r, fsys, name, config)
+		}
+
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			if target := directoryRedirect(r); target != "" {
+				return Empty(http.StatusMovedPermanently).SetHeader("Location", target)
+			}
+		}
+
+		if config.index != "" {
+			index := path.Join(name, config.index)
+
+			if info, err := fs.Stat(fsys, index); err == nil && !info.IsDir() {
+				return serveFile(r, fsys, index, config)
+			}
+		}
+
+		if config.listing {
+			return listDir(fsys, name)
+		}
+
+		return Text("404 page not found").SetStatusCode(http.StatusNotFound)
+	}
+}
+
+// directoryRedirect returns the target which appends a slash to the path of
+// a directory request. The target is relative to the last segment (as with
+// http.FileServer) so that it is correct under http.StripPrefix. A request
+// for the stripped prefix itself has an empty path, in which case the last
+// segment is taken from the original request URI. An empty string is returned
+// if there is no segment to redirect from.
+func directoryRedirect(r *http.Request) string {
+	p := r.URL.Path
+	if p == "" {
+		if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+			p = u.Path
+		}
+	}
+
+	base := path.Base(p)
+	if base == "." || base == "/" {
+		return ""
+	}
+
+	target := base + "/"
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+
+	return target
+}
+
+// newDirConfig applies the given config functions over defaults.
+func newDirConfig(configs []DirConfigFunc) *dirConfig {
+	config := &dirConfig{
+		index:         "index.html",
+		precompressed: []string{"br", "gzip"},
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	return config
+}
+
+// serveFile creates a response containing the named file.
+func serveFile(r *http.Request, fsys fs.FS, name string, config *dirConfig) Response {
+	content, err := openFile(fsys, name, "")
+	if err != nil {
+		return fileError(err)
+	}
+
+	if content.size < 0 {
+		content.file.Close()
+		return Text("404 page not found").SetStatusCode(http.StatusNotFound)
+	}
+
+	contentType, err := detectFileType(name, content.reader)
+	if err != nil {
+		content.file.Close()
+		return failure(err)
+	}
+
+	varies := false
+	for _, encoding := range config.precompressed {
+		ext, ok := precompressedExtensions[encoding]
+		if !ok {
+			continue
+		}
+
+		if info, err := fs.Stat(fsys, name+ext); err != nil || info.IsDir() {
+			continue
+		}
+
+		varies = true
+
+		if acceptsEncoding(r, encoding) {
+			if sidecar, err := openFile(fsys, name+ext, encoding); err == nil && sidecar.size >= 0 {
+				content.file.Close()
+				content = sidecar
+				break
+			}
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("ETag", content.etag)
+
+	if !content.modTime.IsZero() {
+		header.Set("Last-Modified", content.modTime.UTC().Format(http.TimeFormat))
+	}
+
+	if content.encoding != "" {
+		header.Set("Content-Encoding", content.encoding)
+	}
+
+	if varies {
+		header.Set("Vary", "Accept-Encoding")
+	}
+
+	if statusCode := checkPreconditions(r, content); statusCode != 0 {
+		content.file.Close()
+
+		resp := Empty(statusCode)
+		if statusCode == http.StatusNotModified {
+			resp.SetHeader("Content-Length", "")
+
+			for _, name := range []string{"ETag", "Last-Modified", "Vary"} {
+				resp.SetHeader(name, header.Get(name))
+			}
+		}
+
+		return resp
+	}
+
+	start, length, statusCode := selectRange(r, content)
+	if statusCode == http.StatusRequestedRangeNotSatisfiable {
+		content.file.Close()
+
+		resp := Empty(statusCode)
+		resp.SetHeader("Content-Range", fmt.Sprintf("bytes */%d", content.size))
+		return resp
+	}
+
+	if _, err := content.reader.Seek(start, io.SeekStart); err != nil {
+		content.file.Close()
+		return failure(err)
+	}
+
+	resp := Stream(&readCloser{io.LimitReader(content.reader, length), content.file})
+	resp.SetStatusCode(statusCode)
+
+	for k, vs := range header {
+		resp.SetHeader(k, vs[0])
+	}
+
+	resp.SetHeader("Content-Length", strconv.FormatInt(length, 10))
+
+	if statusCode == http.StatusPartialContent {
+		resp.SetHeader("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, content.size))
+	}
+
+	return resp
+}
+
+// openFile opens the named file and computes its validators. A size of -1
+// is returned for directories.
+func openFile(fsys fs.FS, name, encoding string) (*fileContent, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &fileContent{file: file, size: -1}, nil
+	}
+
+	reader, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	content := &fileContent{
+		file:     file,
+		reader:   reader,
+		size:     info.Size(),
+		modTime:  info.ModTime(),
+		encoding: encoding,
+	}
+
+	if content.modTime.IsZero() {
+		// Files without a modification time (such as those of an embed.FS)
+		// are identified by their content instead.
+		h := sha256.New()
+		if _, err := io.Copy(h, reader); err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		content.etag = hex.EncodeToString(h.Sum(nil)[:16])
+	} else {
+		content.etag = fmt.Sprintf("%x-%x", content.modTime.UnixNano(), content.size)
+	}
+
+	if encoding != "" {
+		content.etag += "-" + encoding
+	}
+
+	content.etag = `"` + content.etag + `"`
+	return content, nil
+}
+
+// detectFileType determines the content type of the file from its extension
+// or, failing that, its first 512 bytes. The reader is rewound afterwards.
+func detectFileType(name string, reader io.ReadSeeker) (string, error) {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType, nil
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	buffer := make([]byte, 512)
+	n, err := io.ReadFull(reader, buffer)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	return http.DetectContentType(buffer[:n]), nil
+}
+
+// checkPreconditions evaluates the conditional headers of the request (see
+// RFC 9110, section 13.2.2). A non-zero status code is returned if the
+// request should not receive the file content.
+func checkPreconditions(r *http.Request, content *fileContent) int {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !matchETag(ifMatch, content.etag, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if modifiedAfter(r.Header.Get("If-Unmodified-Since"), content.modTime) {
+		return http.StatusPreconditionFailed
+	}
+
+	notModified := false
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if !matchETag(ifNoneMatch, content.etag, true) {
+			return 0
+		}
+
+		notModified = true
+	} else if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		since := r.Header.Get("If-Modified-Since")
+		notModified = since != "" && !content.modTime.IsZero() && !modifiedAfter(since, content.modTime)
+	}
+
+	if !notModified {
+		return 0
+	}
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return http.StatusNotModified
+	}
+
+	return http.StatusPreconditionFailed
+}
+
+// selectRange determines the portion of the file to send. Requests with
+// a single satisfiable byte range receive partial content. Requests with
+// multiple ranges or a stale If-Range validator receive the whole file.
+func selectRange(r *http.Request, content *fileContent) (int64, int64, int) {
+	header := r.Header.Get("Range")
+	if header == "" || r.Method != http.MethodGet || !strings.HasPrefix(header, "bytes=") || strings.Contains(header, ",") {
+		return 0, content.size, http.StatusOK
+	}
+
+	if ifRange := r.Header.Get("If-Range"); ifRange != "" {
+		if strings.HasPrefix(ifRange, `"`) {
+			if ifRange != content.etag {
+				return 0, content.size, http.StatusOK
+			}
+		} else if modifiedAfter(ifRange, content.modTime) || content.modTime.IsZero() {
+			return 0, content.size, http.StatusOK
+		}
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(header, "bytes=")), "-", 2)
+	if len(parts) != 2 {
+		return 0, content.size, http.StatusOK
+	}
+
+	var start, end int64
+	if parts[0] == "" {
+		suffix, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, content.size, http.StatusOK
+		}
+
+		if suffix == 0 || content.size == 0 {
+			return 0, 0, http.StatusRequestedRangeNotSatisfiable
+		}
+
+		if suffix > content.size {
+			suffix = content.size
+		}
+
+		start, end = content.size-suffix, content.size-1
+	} else {
+		var err error
+		if start, err = strconv.ParseInt(parts[0], 10, 64); err != nil || start < 0 {
+			return 0, content.size, http.StatusOK
+		}
+
+		end = content.size - 1
+		if parts[1] != "" {
+			if end, err = strconv.ParseInt(parts[1], 10, 64); err != nil || end < start {
+				return 0, content.size, http.StatusOK
+			}
+		}
+
+		if start >= content.size {
+			return 0, 0, http.StatusRequestedRangeNotSatisfiable
+		}
+
+		if end >= content.size {
+			end = content.size - 1
+		}
+	}
+
+	return start, end - start + 1, http.StatusPartialContent
+}
+
+// listDir renders an HTML list of the entries of the named directory.
+func listDir(fsys fs.FS, name string) Response {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return fileError(err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+
+		href := (&url.URL{Path: name}).String()
+		fmt.Fprintf(buffer, "<a href=\"%s\">%s</a>\n", html.EscapeString(href), html.EscapeString(name))
+	}
+
+	buffer.WriteString("</pre>\n")
+	return HTML(buffer.String())
+}
+
+// fileError converts an error from the file system into a response.
+func fileError(err error) Response {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return Text("404 page not found").SetStatusCode(http.StatusNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		return Text("403 Forbidden").SetStatusCode(http.StatusForbidden)
+	case errors.Is(err, fs.ErrInvalid):
+		return Text("invalid URL path").SetStatusCode(http.StatusBadRequest)
+	default:
+		return failure(err)
+	}
+}
+
+// matchETag returns true if the given entity tag matches any tag in the
+// header. Weak comparison ignores the W/ prefix of entity tags.
+func matchETag(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// modifiedAfter returns true if the given HTTP date is valid and the
+// modification time is later than that date. HTTP dates have a resolution
+// of one second.
+func modifiedAfter(date string, modTime time.Time) bool {
+	if date == "" || modTime.IsZero() {
+		return false
+	}
+
+	t, err := http.ParseTime(date)
+	if err != nil {
+		return false
+	}
+
+	return modTime.Truncate(time.Second).After(t)
+}
+
+// acceptsEncoding returns true if the Accept-Encoding header of the request
+// lists the given content coding with a non-zero quality.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), encoding) {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			if q := strings.TrimSpace(param); strings.HasPrefix(q, "q=") {
+				if value, err := strconv.ParseFloat(q[2:], 64); err == nil && value == 0 {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// containsDotDot returns true if any segment of the path is "..".
+func containsDotDot(p string) bool {
+	for _, segment := range strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,258 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type FileSuite struct{}
+
+func (s *FileSuite) TestDir(t *testing.T) {
+	handler := Dir(testFiles())
+
+	headers, body, err := Serialize(handler(httptest.NewRequest("GET", "/css/site.css", nil)))
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("body { color: red; }"))
+	Expect(headers.Get("Content-Type")).To(Equal("text/css; charset=utf-8"))
+	Expect(headers.Get("Content-Length")).To(Equal("20"))
+	Expect(headers.Get("Accept-Ranges")).To(Equal("bytes"))
+	Expect(headers.Get("Last-Modified")).To(Equal("Sat, 01 Jun 2024 12:00:00 GMT"))
+	Expect(headers.Get("ETag")).To(MatchRegexp(`^"[0-9a-f]+-14"$`))
+	Expect(headers.Get("Vary")).To(BeEmpty())
+}
+
+func (s *FileSuite) TestDirSniffsContentType(t *testing.T) {
+	headers, _, err := Serialize(Dir(testFiles())(httptest.NewRequest("GET", "/data", nil)))
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Type")).To(Equal("image/png"))
+}
+
+func (s *FileSuite) TestDirNotFound(t *testing.T) {
+	handler := Dir(testFiles())
+
+	Expect(handler(httptest.NewRequest("GET", "/missing.txt", nil)).StatusCode()).To(Equal(http.StatusNotFound))
+	Expect(handler(httptest.NewRequest("GET", "/empty/", nil)).StatusCode()).To(Equal(http.StatusNotFound))
+}
+
+func (s *FileSuite) TestDirTraversal(t *testing.T) {
+	handler := Dir(testFiles())
+
+	for _, p := range []string{"/../secret", "/css/../../secret", `/css/..\..\secret`} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.URL.Path = p
+		Expect(handler(r).StatusCode()).To(Equal(http.StatusBadRequest), p)
+	}
+}
+
+func (s *FileSuite) TestDirIndex(t *testing.T) {
+	handler := Dir(testFiles())
+
+	_, body, _ := Serialize(handler(httptest.NewRequest("GET", "/", nil)))
+	Expect(string(body)).To(Equal("<h1>home</h1>"))
+
+	resp := handler(httptest.NewRequest("GET", "/docs?page=2", nil))
+	Expect(resp.StatusCode()).To(Equal(http.StatusMovedPermanently))
+	Expect(resp.Header("Location")).To(Equal("docs/?page=2"))
+
+	_, body, _ = Serialize(Dir(testFiles(), WithIndexFile("main.html"))(httptest.NewRequest("GET", "/docs/", nil)))
+	Expect(string(body)).To(Equal("<h1>docs</h1>"))
+}
+
+func (s *FileSuite) TestDirRedirectStripPrefix(t *testing.T) {
+	handler := http.StripPrefix("/static", Convert(Dir(testFiles())))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/static/docs", nil))
+	Expect(w.Code).To(Equal(http.StatusMovedPermanently))
+	Expect(w.Header().Get("Location")).To(Equal("docs/"))
+
+	location, err := url.Parse("http://example.com/static/docs")
+	Expect(err).To(BeNil())
+	Expect(location.ResolveReference(&url.URL{Path: w.Header().Get("Location")}).Path).To(Equal("/static/docs/"))
+}
+
+func (s *FileSuite) TestDirRedirectStripPrefixRoot(t *testing.T) {
+	handler := http.StripPrefix("/static", Convert(Dir(testFiles())))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/static?v=1", nil))
+	Expect(w.Code).To(Equal(http.StatusMovedPermanently))
+	Expect(w.Header().Get("Location")).To(Equal("static/?v=1"))
+
+	location, err := url.Parse("http://example.com/static?v=1")
+	Expect(err).To(BeNil())
+	Expect(location.ResolveReference(&url.URL{Path: "static/"}).Path).To(Equal("/static/"))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/static/", nil))
+	Expect(w.Code).To(Equal(http.StatusOK))
+	Expect(w.Body.String()).To(Equal("<h1>home</h1>"))
+}
+
+func (s *FileSuite) TestDirListing(t *testing.T) {
+	handler := Dir(testFiles(), WithDirectoryListing(), WithIndexFile(""))
+
+	headers, body, _ := Serialize(handler(httptest.NewRequest("GET", "/", nil)))
+	Expect(headers.Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+	Expect(string(body)).To(ContainSubstring("<a href=\"css/\">css/</a>\n<a href=\"data\">data</a>\n"))
+	Expect(string(body)).To(ContainSubstring("<a href=\"a%20&amp;%20b.txt\">a &amp; b.txt</a>"))
+}
+
+func (s *FileSuite) TestDirPrecompressed(t *testing.T) {
+	handler := Dir(testFiles())
+
+	r := httptest.NewRequest("GET", "/app.js", nil)
+	r.Header.Set("Accept-Encoding", "gzip, deflate, br;q=0")
+	headers, body, _ := Serialize(handler(r))
+	Expect(string(body)).To(Equal("GZIP"))
+	Expect(headers.Get("Content-Encoding")).To(Equal("gzip"))
+	Expect(headers.Get("Content-Type")).To(Equal("text/javascript; charset=utf-8"))
+	Expect(headers.Get("Vary")).To(Equal("Accept-Encoding"))
+	Expect(headers.Get("ETag")).To(HaveSuffix(`-gzip"`))
+
+	r.Header.Set("Accept-Encoding", "gzip, br")
+	headers, body, _ = Serialize(handler(r))
+	Expect(string(body)).To(Equal("BROTLI"))
+	Expect(headers.Get("Content-Encoding")).To(Equal("br"))
+
+	r.Header.Del("Accept-Encoding")
+	headers, body, _ = Serialize(handler(r))
+	Expect(string(body)).To(Equal("console.log(1)"))
+	Expect(headers.Get("Content-Encoding")).To(BeEmpty())
+	Expect(headers.Get("Vary")).To(Equal("Accept-Encoding"))
+
+	r.Header.Set("Accept-Encoding", "gzip")
+	_, body, _ = Serialize(Dir(testFiles(), WithPrecompressed())(r))
+	Expect(string(body)).To(Equal("console.log(1)"))
+}
+
+func (s *FileSuite) TestConditional(t *testing.T) {
+	handler := Dir(testFiles())
+	etag := handler(httptest.NewRequest("GET", "/css/site.css", nil)).Header("ETag")
+
+	r := httptest.NewRequest("GET", "/css/site.css", nil)
+	r.Header.Set("If-None-Match", `"other", `+etag)
+	headers, body, _ := Serialize(handler(r))
+	Expect(body).To(BeEmpty())
+	Expect(headers.Get("ETag")).To(Equal(etag))
+	Expect(headers.Get("Content-Length")).To(BeEmpty())
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusNotModified))
+
+	r = httptest.NewRequest("GET", "/css/site.css", nil)
+	r.Header.Set("If-Modified-Since", "Sat, 01 Jun 2024 12:00:00 GMT")
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusNotModified))
+	r.Header.Set("If-Modified-Since", "Sat, 01 Jun 2024 11:59:59 GMT")
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusOK))
+
+	r = httptest.NewRequest("PUT", "/css/site.css", nil)
+	r.Header.Set("If-None-Match", "*")
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusPreconditionFailed))
+
+	r = httptest.NewRequest("GET", "/css/site.css", nil)
+	r.Header.Set("If-Match", `"other"`)
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusPreconditionFailed))
+	r.Header.Set("If-Match", etag)
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusOK))
+}
+
+func (s *FileSuite) TestRange(t *testing.T) {
+	handler := Dir(testFiles())
+
+	for _, testCase := range []struct {
+		header       string
+		statusCode   int
+		body         string
+		contentRange string
+	}{
+		{"bytes=0-3", http.StatusPartialContent, "body", "bytes 0-3/20"},
+		{"bytes=16-", http.StatusPartialContent, "d; }", "bytes 16-19/20"},
+		{"bytes=-2", http.StatusPartialContent, " }", "bytes 18-19/20"},
+		{"bytes=5-100", http.StatusPartialContent, "{ color: red; }", "bytes 5-19/20"},
+		{"bytes=0-1,3-4", http.StatusOK, "body { color: red; }", ""},
+		{"bytes=5-2", http.StatusOK, "body { color: red; }", ""},
+		{"items=0-1", http.StatusOK, "body { color: red; }", ""},
+		{"bytes=20-", http.StatusRequestedRangeNotSatisfiable, "", "bytes */20"},
+	} {
+		r := httptest.NewRequest("GET", "/css/site.css", nil)
+		r.Header.Set("Range", testCase.header)
+
+		resp := handler(r)
+		Expect(resp.StatusCode()).To(Equal(testCase.statusCode), testCase.header)
+		headers, body, _ := Serialize(resp)
+		Expect(string(body)).To(Equal(testCase.body), testCase.header)
+		Expect(headers.Get("Content-Range")).To(Equal(testCase.contentRange), testCase.header)
+	}
+}
+
+func (s *FileSuite) TestRangeIfRange(t *testing.T) {
+	handler := Dir(testFiles())
+	etag := handler(httptest.NewRequest("GET", "/css/site.css", nil)).Header("ETag")
+
+	r := httptest.NewRequest("GET", "/css/site.css", nil)
+	r.Header.Set("Range", "bytes=0-3")
+	r.Header.Set("If-Range", etag)
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusPartialContent))
+
+	r.Header.Set("If-Range", `"stale"`)
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusOK))
+
+	r.Header.Set("If-Range", "Sat, 01 Jun 2024 12:00:00 GMT")
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusPartialContent))
+	r.Header.Set("If-Range", "Sat, 01 Jun 2024 11:00:00 GMT")
+	Expect(handler(r).StatusCode()).To(Equal(http.StatusOK))
+}
+
+func (s *FileSuite) TestContentETag(t *testing.T) {
+	fsys := fstest.MapFS{"file.txt": {Data: []byte("content")}}
+
+	headers, _, _ := Serialize(Dir(fsys)(httptest.NewRequest("GET", "/file.txt", nil)))
+	Expect(headers.Get("ETag")).To(Equal(`"ed7002b439e9ac845f22357d822bac14"`))
+	Expect(headers.Get("Last-Modified")).To(BeEmpty())
+}
+
+func (s *FileSuite) TestFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "response")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "report.json")
+	Expect(os.WriteFile(name, []byte(`{"ok":true}`), 0644)).To(BeNil())
+
+	headers, body, err := Serialize(File(httptest.NewRequest("GET", "/download", nil), name))
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal(`{"ok":true}`))
+	Expect(headers.Get("Content-Type")).To(Equal("application/json"))
+
+	resp := File(httptest.NewRequest("GET", "/download", nil), filepath.Join(dir, "missing"))
+	Expect(resp.StatusCode()).To(Equal(http.StatusNotFound))
+	resp = File(httptest.NewRequest("GET", "/download", nil), dir)
+	Expect(resp.StatusCode()).To(Equal(http.StatusNotFound))
+}
+
+//
+//
+
+func testFiles() fstest.MapFS {
+	modTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	return fstest.MapFS{
+		"index.html":      {Data: []byte("<h1>home</h1>"), ModTime: modTime},
+		"a & b.txt":       {Data: []byte("ab"), ModTime: modTime},
+		"css/site.css":    {Data: []byte("body { color: red; }"), ModTime: modTime},
+		"data":            {Data: []byte("\x89PNG\x0D\x0A\x1A\x0A"), ModTime: modTime},
+		"docs/main.html":  {Data: []byte("<h1>docs</h1>"), ModTime: modTime},
+		"empty":           {Mode: 0755 | os.ModeDir, ModTime: modTime},
+		"app.js":          {Data: []byte("console.log(1)"), ModTime: modTime},
+		"app.js.gz":       {Data: []byte("GZIP"), ModTime: modTime},
+		"app.js.br":       {Data: []byte("BROTLI"), ModTime: modTime},
+		"secret/password": {Data: []byte("hunter2")},
+	}
+}
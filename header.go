@@ -0,0 +1,120 @@
+package response
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+type (
+	// HeaderPolicy determines how a response treats header and trailer
+	// names and values which are not valid field syntax (see RFC 9110).
+	HeaderPolicy int
+
+	// HeaderError describes a header or trailer which failed validation.
+	HeaderError struct {
+		Name   string
+		Value  string
+		Reason string
+	}
+)
+
+const (
+	// HeaderPolicyReject leaves invalid headers unset and records an error.
+	HeaderPolicyReject HeaderPolicy = iota
+
+	// HeaderPolicySanitize removes invalid characters from names and
+	// replaces control characters in values with spaces, then sets the
+	// header. An error is still recorded.
+	HeaderPolicySanitize
+
+	// HeaderPolicyPanic panics on invalid headers. This is the default
+	// policy of builds with the response_debug tag.
+	HeaderPolicyPanic
+)
+
+// defaultPolicy holds the HeaderPolicy applied to new responses.
+var defaultPolicy = int32(defaultHeaderPolicy)
+
+// DefaultHeaderPolicy returns the policy applied to newly created responses.
+func DefaultHeaderPolicy() HeaderPolicy {
+	return HeaderPolicy(atomic.LoadInt32(&defaultPolicy))
+}
+
+// SetDefaultHeaderPolicy sets the policy applied to responses created after
+// it is called. This is safe to call while serving, but is generally called
+// once during initialization. Use the SetHeaderPolicy method to change the
+// policy of a single response.
+func SetDefaultHeaderPolicy(policy HeaderPolicy) {
+	atomic.StoreInt32(&defaultPolicy, int32(policy))
+}
+
+// Error implements the error interface.
+func (e *HeaderError) Error() string {
+	return fmt.Sprintf("invalid header %q: %s", e.Name, e.Reason)
+}
+
+// validateField checks the given field name and value. If the field is
+// valid, the name and value are returned with surrounding whitespace removed
+// from the value. Otherwise, a non-nil error is returned along with the
+// sanitized name and value. A sanitized name may be empty, in which case
+// the field cannot be set.
+func validateField(name, value string) (string, string, error) {
+	value = strings.Trim(value, " \t")
+
+	if name == "" {
+		return "", value, &HeaderError{name, value, "empty name"}
+	}
+
+	for i := 0; i < len(name); i++ {
+		if !isTokenChar(name[i]) {
+			return sanitizeName(name), sanitizeValue(value), &HeaderError{name, value, fmt.Sprintf("invalid character %q in name", name[i])}
+		}
+	}
+
+	for i := 0; i < len(value); i++ {
+		if !isFieldValueChar(value[i]) {
+			return name, sanitizeValue(value), &HeaderError{name, value, fmt.Sprintf("invalid character %q in value", value[i])}
+		}
+	}
+
+	return name, value, nil
+}
+
+// sanitizeName removes all non-token characters from the name.
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x80 && isTokenChar(byte(r)) {
+			return r
+		}
+
+		return -1
+	}, name)
+}
+
+// sanitizeValue replaces all control characters in the value with spaces.
+func sanitizeValue(value string) string {
+	sanitized := []byte(value)
+	for i, c := range sanitized {
+		if !isFieldValueChar(c) {
+			sanitized[i] = ' '
+		}
+	}
+
+	return strings.Trim(string(sanitized), " \t")
+}
+
+// isTokenChar returns true if the character is a valid tchar.
+func isTokenChar(c byte) bool {
+	if c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+		return true
+	}
+
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+// isFieldValueChar returns true if the character is a visible character,
+// obs-text, space, or horizontal tab.
+func isFieldValueChar(c byte) bool {
+	return c == ' ' || c == '\t' || (c > 0x20 && c != 0x7f)
+}
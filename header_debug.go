@@ -0,0 +1,5 @@
+//go:build response_debug
+
+package response
+
+const defaultHeaderPolicy = HeaderPolicyPanic
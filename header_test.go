@@ -0,0 +1,96 @@
+package response
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type HeaderSuite struct{}
+
+func (s *HeaderSuite) TestValidateField(t *testing.T) {
+	name, value, err := validateField("X-Foo", " bar\tbaz ")
+	Expect(err).To(BeNil())
+	Expect(name).To(Equal("X-Foo"))
+	Expect(value).To(Equal("bar\tbaz"))
+
+	_, _, err = validateField("X-Foo", "caf\xc3\xa9")
+	Expect(err).To(BeNil())
+
+	name, value, err = validateField("X-Foo", "bar\r\nSet-Cookie: evil=1")
+	Expect(err).To(MatchError(`invalid header "X-Foo": invalid character '\r' in value`))
+	Expect(name).To(Equal("X-Foo"))
+	Expect(value).To(Equal("bar  Set-Cookie: evil=1"))
+
+	name, value, err = validateField("X Foo:", "bar")
+	Expect(err).To(MatchError(`invalid header "X Foo:": invalid character ' ' in name`))
+	Expect(name).To(Equal("XFoo"))
+	Expect(value).To(Equal("bar"))
+
+	_, _, err = validateField("", "bar")
+	Expect(err).To(MatchError(`invalid header "": empty name`))
+}
+
+func (s *HeaderSuite) TestRejectPolicy(t *testing.T) {
+	resp := Respond(nil).SetHeaderPolicy(HeaderPolicyReject)
+	resp.SetHeader("X-Foo", "bar\r\nbaz")
+	resp.AddHeader("X-Foo", "bonk\x00")
+	resp.AddHeader("X(Bar)", "bonk")
+	resp.AddTrailer("X-Baz", "\n")
+	resp.SetHeader("X-Valid", "ok")
+
+	Expect(resp.HeaderErrors()).To(HaveLen(4))
+	Expect(resp.HeaderErrors()[0]).To(Equal(&HeaderError{"X-Foo", "bar\r\nbaz", `invalid character '\r' in value`}))
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers).NotTo(HaveKey("X-Foo"))
+	Expect(headers).NotTo(HaveKey("Trailer"))
+	Expect(headers.Get("X-Valid")).To(Equal("ok"))
+}
+
+func (s *HeaderSuite) TestSanitizePolicy(t *testing.T) {
+	resp := Respond(nil).SetHeaderPolicy(HeaderPolicySanitize)
+	resp.SetHeader("X-Foo", "bar\r\nbaz")
+	resp.AddHeader("X(Bar)", "bonk")
+	resp.AddHeader("()", "bonk")
+	resp.AddTrailerFunc("X-Status", func(error) string { return "done\n" })
+
+	Expect(resp.HeaderErrors()).To(HaveLen(3))
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers.Get("X-Foo")).To(Equal("bar  baz"))
+	Expect(headers.Get("XBar")).To(Equal("bonk"))
+	Expect(headers.Get(http.TrailerPrefix + "X-Status")).To(Equal("done"))
+	Expect(resp.HeaderErrors()).To(HaveLen(4))
+}
+
+func (s *HeaderSuite) TestPanicPolicy(t *testing.T) {
+	resp := Respond(nil).SetHeaderPolicy(HeaderPolicyPanic)
+	Expect(func() { resp.SetHeader("X-Foo", "bar") }).NotTo(Panic())
+	Expect(func() { resp.SetHeader("X-Foo", "bar\nbaz") }).To(Panic())
+	Expect(func() { resp.AddTrailerFunc("X Foo", nil) }).To(Panic())
+}
+
+func (s *HeaderSuite) TestDefaultPolicy(t *testing.T) {
+	defer SetDefaultHeaderPolicy(DefaultHeaderPolicy())
+
+	SetDefaultHeaderPolicy(HeaderPolicySanitize)
+	Expect(DefaultHeaderPolicy()).To(Equal(HeaderPolicySanitize))
+
+	resp := Empty(http.StatusOK).SetHeader("X-Foo", "bar\nbaz")
+	Expect(resp.Header("X-Foo")).To(Equal("bar baz"))
+}
+
+func (s *HeaderSuite) TestSetHeaderPolicy(t *testing.T) {
+	resp := Empty(http.StatusOK)
+	resp.SetHeader("X-Foo", "bar\nbaz")
+	Expect(resp.Header("X-Foo")).To(BeEmpty())
+
+	Expect(resp.SetHeaderPolicy(HeaderPolicySanitize)).To(Equal(resp))
+	resp.SetHeader("X-Foo", "bar\nbaz")
+	Expect(resp.Header("X-Foo")).To(Equal("bar baz"))
+	Expect(resp.HeaderErrors()).To(HaveLen(2))
+}
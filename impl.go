@@ -3,16 +3,37 @@ package response
 import (
 	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type (
 	// response implements the Response interface.
 	response struct {
-		statusCode int
-		header     http.Header
-		writer     bodyWriter
-		callbacks  []CallbackFunc
-		written    bool
+		statusCode   int
+		header       http.Header
+		trailer      http.Header
+		trailerFuncs []trailerFunc
+		writer       bodyWriter
+		body         []byte
+		buffered     bool
+		peek         func(n int) []byte
+		decorated    bool
+		beforeHeader []func()
+		release      func()
+		err          error
+		callbacks    []CallbackFunc
+		policy       HeaderPolicy
+		headerErrors []error
+		written      bool
+	}
+
+	// trailerFunc pairs a lazily computed trailer value with its name.
+	trailerFunc struct {
+		name string
+		f    TrailerFunc
 	}
 
 	// bodyWriter is the core of a response - it's a function that
@@ -24,12 +45,27 @@ type (
 // ensure we conform to interface
 var _ Response = &response{}
 
+// newBufferedResponse creates a response which writes the given body.
+// The content of the body remains available to functions which need to
+// inspect it before it is written (until the writer is decorated).
+func newBufferedResponse(data []byte) *response {
+	resp := newResponse(func(w io.Writer) error {
+		return writeAll(w, data)
+	})
+
+	resp.body = data
+	resp.buffered = true
+	return resp
+}
+
 // newResponse creates a response with the given body writer.
-func newResponse(writer bodyWriter) Response {
+func newResponse(writer bodyWriter) *response {
 	return &response{
 		statusCode: http.StatusOK,
 		header:     make(http.Header),
+		trailer:    make(http.Header),
 		writer:     writer,
+		policy:     DefaultHeaderPolicy(),
 	}
 }
 
@@ -49,23 +85,157 @@ func (r *response) SetStatusCode(statusCode int) Response {
 	return r
 }
 
-// SetHeader sets the value of this header.
+// SetHeader sets the value of this header. Invalid names and values
+// are handled according to the header policy of the response.
 func (r *response) SetHeader(header, val string) Response {
 	if val == "" {
 		r.header.Del(header)
-	} else {
+	} else if header, val, ok := r.validate(header, val); ok {
 		r.header.Set(header, val)
 	}
 
 	return r
 }
 
-// AddHeader adds another value to this header.
+// AddHeader adds another value to this header. Invalid names and values
+// are handled according to the header policy of the response.
 func (r *response) AddHeader(header, val string) Response {
-	r.header.Add(header, val)
+	if header, val, ok := r.validate(header, val); ok {
+		r.header.Add(header, val)
+	}
+
+	return r
+}
+
+// Trailer retrieves the first value set to this trailer. Values of
+// trailers registered via AddTrailerFunc are not visible here.
+func (r *response) Trailer(trailer string) string {
+	return r.trailer.Get(trailer)
+}
+
+// SetTrailer sets the value of this trailer. Invalid names and values
+// are handled according to the header policy of the response.
+func (r *response) SetTrailer(trailer, val string) Response {
+	if val == "" {
+		r.trailer.Del(trailer)
+	} else if trailer, val, ok := r.validate(trailer, val); ok {
+		r.trailer.Set(trailer, val)
+	}
+
+	return r
+}
+
+// AddTrailer adds another value to this trailer. Invalid names and values
+// are handled according to the header policy of the response.
+func (r *response) AddTrailer(trailer, val string) Response {
+	if trailer, val, ok := r.validate(trailer, val); ok {
+		r.trailer.Add(trailer, val)
+	}
+
+	return r
+}
+
+// AddTrailerFunc registers a function which computes a value for this
+// trailer after the entire response body has been written. The name of
+// the trailer is declared to the client before the body is sent.
+func (r *response) AddTrailerFunc(trailer string, f TrailerFunc) Response {
+	if trailer, _, ok := r.validate(trailer, ""); ok {
+		r.trailerFuncs = append(r.trailerFuncs, trailerFunc{
+			name: http.CanonicalHeaderKey(trailer),
+			f:    f,
+		})
+	}
+
+	return r
+}
+
+// SetCookie adds a Set-Cookie header for the given cookie. Any cookie
+// previously set with the same name, path, and domain is replaced. The
+// Secure attribute is added to cookies which are partitioned or which have
+// the SameSite attribute set to None, as browsers ignore these cookies
+// otherwise. Invalid cookies are handled according to the header policy of
+// the response.
+func (r *response) SetCookie(cookie *http.Cookie) Response {
+	c := *cookie
+	if c.Partitioned || c.SameSite == http.SameSiteNoneMode {
+		c.Secure = true
+	}
+
+	if err := c.Valid(); err != nil {
+		if r.policy == HeaderPolicyPanic {
+			panic(err.Error())
+		}
+
+		r.headerErrors = append(r.headerErrors, &HeaderError{"Set-Cookie", c.Name, err.Error()})
+		return r
+	}
+
+	values := []string{}
+	for _, value := range r.header["Set-Cookie"] {
+		if existing, err := http.ParseSetCookie(value); err != nil || !sameCookie(existing, &c) {
+			values = append(values, value)
+		}
+	}
+
+	r.header["Set-Cookie"] = append(values, c.String())
+	return r
+}
+
+// ClearCookie instructs the client to remove the cookie with the given
+// name, path, and domain.
+func (r *response) ClearCookie(name, path, domain string) Response {
+	return r.SetCookie(&http.Cookie{
+		Name:    name,
+		Path:    path,
+		Domain:  domain,
+		Expires: time.Unix(1, 0),
+		MaxAge:  -1,
+	})
+}
+
+// sameCookie returns true if both cookies have the same name, path, and
+// domain.
+func sameCookie(c1, c2 *http.Cookie) bool {
+	return c1.Name == c2.Name &&
+		c1.Path == c2.Path &&
+		strings.TrimPrefix(c1.Domain, ".") == strings.TrimPrefix(c2.Domain, ".")
+}
+
+// HeaderErrors returns the validation failures of headers and trailers
+// set on this response.
+func (r *response) HeaderErrors() []error {
+	return r.headerErrors
+}
+
+// SetHeaderPolicy sets the policy applied to headers and trailers which
+// are set on this response after this call.
+func (r *response) SetHeaderPolicy(policy HeaderPolicy) Response {
+	r.policy = policy
 	return r
 }
 
+// validate checks the given field name and value against the header
+// policy of the response. The (possibly sanitized) name and value are
+// returned along with a flag indicating whether the field should be set.
+func (r *response) validate(name, val string) (string, string, bool) {
+	sanitizedName, sanitizedVal, err := validateField(name, val)
+	if err == nil {
+		return sanitizedName, sanitizedVal, true
+	}
+
+	if r.policy == HeaderPolicyPanic {
+		panic(err.Error())
+	}
+
+	r.headerErrors = append(r.headerErrors, err)
+
+	if r.policy == HeaderPolicySanitize && sanitizedName != "" {
+		return sanitizedName, sanitizedVal, true
+	}
+
+	return "", "", false
+}
+
 // AddCallback registers a callback to be invoked on after the entire
 // response body has been written to the client. If any error occurred
 // during the send, it is made available to the function registered here.
@@ -81,6 +251,8 @@ func (r *response) AddCallback(f CallbackFunc) Response {
 // decorated writer is closed.
 func (r *response) DecorateWriter(f WriterDecorator) Response {
 	baseWriter := r.writer
+	r.buffered = false
+	r.decorated = true
 
 	r.writer = func(w io.Writer) error {
 		decorated := f(w)
@@ -130,24 +302,111 @@ func (r *response) WriteTo(w http.ResponseWriter) {
 	}
 
 	r.written = true
+
+	for _, f := range r.beforeHeader {
+		f()
+	}
+
 	r.writeHeader(w)
 	err := r.writeBody(w)
+	r.writeTrailers(w, err)
 
 	for _, c := range r.callbacks {
 		c(err)
 	}
 }
 
+// writeHead writes only the headers and status code of the response to
+// the ResponseWriter, as is appropriate for a HEAD request. The body writer
+// is never invoked, but any resources held by the body are released. The
+// Content-Length header of an unmodified buffered body is set if absent.
+// Callbacks receive the error the body is known to produce (see failure),
+// if any. Like WriteTo, this method will panic when called multiple times.
+func (r *response) writeHead(w http.ResponseWriter) {
+	if r.written {
+		panic("response was already written")
+	}
+
+	r.written = true
+
+	for _, f := range r.beforeHeader {
+		f()
+	}
+
+	if r.buffered && r.header.Get("Content-Length") == "" {
+		r.header.Set("Content-Length", strconv.Itoa(len(r.body)))
+	}
+
+	r.writeHeader(w)
+
+	if r.release != nil {
+		r.release()
+	}
+
+	for _, c := range r.callbacks {
+		c(r.err)
+	}
+}
+
 // writeHeader writes the headers and status code to the response writer.
+// The Content-Length header is omitted when trailers are declared, as the
+// server only sends trailers with a chunked body.
 func (r *response) writeHeader(w http.ResponseWriter) {
 	header := w.Header()
 	for k, v := range r.header {
 		header[k] = v
 	}
 
+	names := r.trailerNames()
+	for _, name := range names {
+		header.Add("Trailer", name)
+	}
+
+	if len(names) > 0 {
+		header.Del("Content-Length")
+	}
+
 	w.WriteHeader(r.statusCode)
 }
 
+// writeTrailers sets the values of all declared trailers on the response
+// writer. This must be called after the entire body has been written.
+func (r *response) writeTrailers(w http.ResponseWriter, err error) {
+	header := w.Header()
+	for k, v := range r.trailer {
+		header[k] = v
+	}
+
+	for _, t := range r.trailerFuncs {
+		if val := t.f(err); val != "" {
+			if name, val, ok := r.validate(t.name, val); ok {
+				header.Add(name, val)
+			}
+		}
+	}
+}
+
+// trailerNames returns the sorted and de-duplicated names of all static
+// and lazily computed trailers.
+func (r *response) trailerNames() []string {
+	set := map[string]struct{}{}
+	for k := range r.trailer {
+		set[k] = struct{}{}
+	}
+
+	for _, t := range r.trailerFuncs {
+		set[t.name] = struct{}{}
+	}
+
+	names := []string{}
+	for name := range set {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
 // writeBody writes the entire body to the response writer (if any writer
 // is supplied).
 func (r *response) writeBody(w http.ResponseWriter) error {
@@ -8,14 +8,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"testing"
 
-	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
 )
 
 type ImplementationSuite struct{}
 
-func (s *ImplementationSuite) TestSetters(t sweet.T) {
+func (s *ImplementationSuite) TestSetters(t *testing.T) {
 	resp := newResponse(nil)
 	Expect(resp.StatusCode()).To(Equal(http.StatusOK))
 	Expect(resp.SetStatusCode(http.StatusNotFound)).To(Equal(resp))
@@ -34,7 +34,66 @@ func (s *ImplementationSuite) TestSetters(t sweet.T) {
 	Expect(w.Header()["X-Foo"]).To(Equal([]string{"baz", "bonk"}))
 }
 
-func (s *ImplementationSuite) TestDecorateWriter(t sweet.T) {
+func (s *ImplementationSuite) TestTrailers(t *testing.T) {
+	resp := Respond([]byte("body"))
+	Expect(resp.SetTrailer("X-Foo", "bar")).To(Equal(resp))
+	Expect(resp.Trailer("X-Foo")).To(Equal("bar"))
+	Expect(resp.AddTrailer("X-Foo", "baz")).To(Equal(resp))
+	Expect(resp.Trailer("X-Foo")).To(Equal("bar"))
+	Expect(resp.SetTrailer("X-Bar", "bonk")).To(Equal(resp))
+	Expect(resp.SetTrailer("X-Bar", "")).To(Equal(resp))
+	Expect(resp.Trailer("X-Bar")).To(BeEmpty())
+
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+	result := w.Result()
+	Expect(result.Header["Trailer"]).To(Equal([]string{"X-Foo"}))
+	Expect(result.Trailer["X-Foo"]).To(Equal([]string{"bar", "baz"}))
+}
+
+func (s *ImplementationSuite) TestTrailerFuncs(t *testing.T) {
+	var (
+		r     = ioutil.NopCloser(bytes.NewReader([]byte(`abcdefg`)))
+		resp  = Stream(r)
+		count = 0
+	)
+
+	resp.DecorateWriter(func(w io.Writer) io.Writer {
+		return WriterFunc(func(p []byte) (int, error) {
+			count += len(p)
+			return w.Write(p)
+		})
+	})
+
+	resp.AddTrailerFunc("X-Count", func(err error) string {
+		return fmt.Sprintf("%d", count)
+	})
+
+	resp.AddTrailerFunc("X-Error", func(err error) string {
+		if err != nil {
+			return err.Error()
+		}
+
+		return ""
+	})
+
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+	result := w.Result()
+	Expect(result.Header["Trailer"]).To(Equal([]string{"X-Count", "X-Error"}))
+	Expect(result.Trailer).To(Equal(http.Header{"X-Count": []string{"7"}}))
+}
+
+func (s *ImplementationSuite) TestTrailerFuncsReceiveError(t *testing.T) {
+	resp := Stream(&closer{bytes.NewReader(makeData()), false})
+	resp.AddTrailerFunc("X-Error", func(err error) string { return err.Error() })
+
+	writer := NewFailingResponseWriter(3, fmt.Errorf("utoh"))
+	resp.WriteTo(writer)
+	Expect(writer.Header().Get("X-Error")).To(Equal("utoh"))
+}
+
+func (s *ImplementationSuite) TestDecorateWriter(t *testing.T) {
 	r := ioutil.NopCloser(bytes.NewReader([]byte(`abcdefg`)))
 	resp := Stream(r)
 
@@ -47,7 +106,7 @@ func (s *ImplementationSuite) TestDecorateWriter(t sweet.T) {
 	Expect(body).To(Equal([]byte("ABCDEFG")))
 }
 
-func (s *ImplementationSuite) TestDecorateWriterCloseError(t sweet.T) {
+func (s *ImplementationSuite) TestDecorateWriterCloseError(t *testing.T) {
 	r := ioutil.NopCloser(bytes.NewReader([]byte(`abcdefg`)))
 	resp := Stream(r)
 
@@ -67,7 +126,7 @@ func upperBytes(p []byte) []byte {
 	return []byte(strings.ToUpper(string(p)))
 }
 
-func (s *ImplementationSuite) TestDecorateWriterCloseNotifier(t sweet.T) {
+func (s *ImplementationSuite) TestDecorateWriterCloseNotifier(t *testing.T) {
 	resp := Stream(ioutil.NopCloser(&infiniteReader{}))
 
 	resp.DecorateWriter(func(w io.Writer) io.Writer {
@@ -96,7 +155,7 @@ func (s *ImplementationSuite) TestDecorateWriterCloseNotifier(t sweet.T) {
 	Eventually(ch).Should(Receive())
 }
 
-func (s *ImplementationSuite) TestMultipleWriteToCallsPanics(t sweet.T) {
+func (s *ImplementationSuite) TestMultipleWriteToCallsPanics(t *testing.T) {
 	resp := JSON(nil)
 
 	// This one is fine
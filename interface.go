@@ -4,6 +4,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 )
 
 type (
@@ -24,6 +25,40 @@ type (
 		// AddHeader adds another value to this header.
 		AddHeader(header, val string) Response
 
+		// Trailer retrieves the first value set to this trailer.
+		Trailer(trailer string) string
+
+		// SetTrailer sets the value of this trailer. Trailers are only sent
+		// with a chunked body, so the Content-Length header (even if set
+		// explicitly) is omitted from a response which declares trailers.
+		SetTrailer(trailer, val string) Response
+
+		// AddTrailer adds another value to this trailer. As with SetTrailer,
+		// the Content-Length header is omitted.
+		AddTrailer(trailer, val string) Response
+
+		// AddTrailerFunc registers a function which computes a value for
+		// this trailer after the entire response body has been written. As
+		// with SetTrailer, the Content-Length header is omitted.
+		AddTrailerFunc(trailer string, f TrailerFunc) Response
+
+		// SetCookie adds a Set-Cookie header for the given cookie. Any
+		// cookie previously set with the same name, path, and domain is
+		// replaced.
+		SetCookie(cookie *http.Cookie) Response
+
+		// ClearCookie instructs the client to remove the cookie with the
+		// given name, path, and domain.
+		ClearCookie(name, path, domain string) Response
+
+		// HeaderErrors returns the validation failures of headers and
+		// trailers set on this response.
+		HeaderErrors() []error
+
+		// SetHeaderPolicy sets the policy applied to headers and trailers
+		// which are set on this response after this call.
+		SetHeaderPolicy(policy HeaderPolicy) Response
+
 		// AddCallback registers a callback to be invoked on after
 		// the entire response body has been written to the client.
 		AddCallback(f CallbackFunc) Response
@@ -41,17 +76,27 @@ type (
 	// fails to write to the remote end.
 	CallbackFunc func(error)
 
+	// TrailerFunc computes the value of a trailer once the body has been
+	// written. The function receives the error which occurred while writing
+	// the body (if any). An empty return value omits the trailer.
+	TrailerFunc func(error) string
+
 	// WriterDecorator returns an io.Writer that writes to the given io.Writer.
 	WriterDecorator func(io.Writer) io.Writer
 
 	// HandlerFunc is an analog of an http.HandlerFunc that returns a
 	// response object instead of writing directly to a ResponseWriter.
 	HandlerFunc func(*http.Request) Response
+
+	// Middleware wraps a HandlerFunc with additional behavior.
+	Middleware func(HandlerFunc) HandlerFunc
 )
 
 // Serialize reads the entire response and returns the headers and a
 // byte slice containing the content of the entire body. An error is
-// returned if writing to the response recorder fails.
+// returned if writing to the response recorder fails. Trailers sent
+// after the body are included in the returned headers with their name
+// prefixed by http.TrailerPrefix.
 func Serialize(r Response) (http.Header, []byte, error) {
 	w := httptest.NewRecorder()
 
@@ -59,28 +104,59 @@ func Serialize(r Response) (http.Header, []byte, error) {
 	r.AddCallback(func(e error) { err = e })
 	r.WriteTo(w)
 
-	return w.HeaderMap, w.Body.Bytes(), err
+	result := w.Result()
+	for k, vs := range result.Trailer {
+		result.Header[http.TrailerPrefix+k] = vs
+	}
+
+	return result.Header, w.Body.Bytes(), err
 }
 
 // Reconstruct creates a response from the values returned from Serialize.
+// Headers prefixed by http.TrailerPrefix are restored as trailers.
 func Reconstruct(statusCode int, headers http.Header, body []byte) Response {
 	resp := Respond(body)
 	resp.SetStatusCode(statusCode)
 
 	for k, vs := range headers {
-		resp.SetHeader(k, vs[0])
+		set, add := resp.SetHeader, resp.AddHeader
+
+		if k == "Trailer" {
+			// Declared again by the trailers themselves
+			continue
+		}
+
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			k = strings.TrimPrefix(k, http.TrailerPrefix)
+			set, add = resp.SetTrailer, resp.AddTrailer
+		}
+
+		set(k, vs[0])
 
 		for _, v := range vs[1:] {
-			resp.AddHeader(k, v)
+			add(k, v)
 		}
 	}
 
 	return resp
 }
 
-// Convert converts a HandlerFunc to an http.HandlerFunc.
+// Convert converts a HandlerFunc to an http.HandlerFunc. For HEAD requests,
+// only the headers of the response are written: the body writer is skipped,
+// stream sources are closed without being read, and callbacks are invoked
+// with a nil error (or the error of a response which failed to be created,
+// such as JSON of a value which cannot be serialized).
 func Convert(f HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		f(r).WriteTo(w)
+		resp := f(r)
+
+		if r.Method == http.MethodHead {
+			if resp, ok := resp.(*response); ok {
+				resp.writeHead(w)
+				return
+			}
+		}
+
+		resp.WriteTo(w)
 	})
 }
@@ -5,14 +5,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"testing"
 
-	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
 )
 
 type InterfaceSuite struct{}
 
-func (s *InterfaceSuite) TestRoundTripSerialize(t sweet.T) {
+func (s *InterfaceSuite) TestRoundTripSerialize(t *testing.T) {
 	resp1 := JSON(map[string]interface{}{"foo": []int{1, 2, 3}})
 	resp1.SetStatusCode(http.StatusAccepted)
 	resp1.AddHeader("foo", "bar")
@@ -31,7 +31,63 @@ func (s *InterfaceSuite) TestRoundTripSerialize(t sweet.T) {
 	Expect(resp1.StatusCode()).To(Equal(resp2.StatusCode()))
 }
 
-func (s *InterfaceSuite) TestConvert(t sweet.T) {
+func (s *InterfaceSuite) TestRoundTripSerializeTrailers(t *testing.T) {
+	resp1 := Respond([]byte("content"))
+	resp1.AddTrailer("X-Checksum", "abc")
+	resp1.AddTrailerFunc("X-Rows", func(error) string { return "12" })
+
+	headers1, body1, err := Serialize(resp1)
+	Expect(err).To(BeNil())
+	Expect(headers1["Trailer"]).To(Equal([]string{"X-Checksum", "X-Rows"}))
+	Expect(headers1[http.TrailerPrefix+"X-Checksum"]).To(Equal([]string{"abc"}))
+	Expect(headers1[http.TrailerPrefix+"X-Rows"]).To(Equal([]string{"12"}))
+
+	resp2 := Reconstruct(resp1.StatusCode(), headers1, body1)
+	Expect(resp2.Trailer("X-Rows")).To(Equal("12"))
+	headers2, body2, err := Serialize(resp2)
+	Expect(err).To(BeNil())
+
+	Expect(headers1).To(Equal(headers2))
+	Expect(body1).To(Equal(body2))
+}
+
+func (s *InterfaceSuite) TestConvertTrailers(t *testing.T) {
+	server := httptest.NewServer(Convert(func(r *http.Request) Response {
+		resp := Stream(ioutil.NopCloser(bytes.NewReader([]byte("content"))))
+		resp.AddTrailerFunc("X-Status", func(err error) string { return "done" })
+		return resp
+	}))
+
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	Expect(err).To(BeNil())
+	defer resp.Body.Close()
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	Expect(data).To(Equal([]byte("content")))
+	Expect(resp.Trailer.Get("X-Status")).To(Equal("done"))
+}
+
+func (s *InterfaceSuite) TestConvertTrailersWithContentLength(t *testing.T) {
+	server := httptest.NewServer(Convert(func(r *http.Request) Response {
+		return Respond([]byte("content")).SetTrailer("X-Sum", "abc")
+	}))
+
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	Expect(err).To(BeNil())
+	defer resp.Body.Close()
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	Expect(data).To(Equal([]byte("content")))
+	Expect(resp.ContentLength).To(Equal(int64(-1)))
+	Expect(resp.TransferEncoding).To(Equal([]string{"chunked"}))
+	Expect(resp.Trailer.Get("X-Sum")).To(Equal("abc"))
+}
+
+func (s *InterfaceSuite) TestConvert(t *testing.T) {
 	var (
 		errors = make(chan error, 2)
 		c1     = func(err error) { errors <- err }
@@ -69,3 +125,65 @@ func (s *InterfaceSuite) TestConvert(t sweet.T) {
 	Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
 	Expect(data).To(MatchJSON(`{"input": "content"}`))
 }
+
+func (s *InterfaceSuite) TestConvertHead(t *testing.T) {
+	errors := make(chan error, 1)
+
+	handler := Convert(func(r *http.Request) Response {
+		resp := Respond([]byte("content"))
+		resp.SetHeader("Content-Length", "")
+		resp.AddCallback(func(err error) { errors <- err })
+		return resp
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("HEAD", "/", nil))
+	Expect(w.Code).To(Equal(http.StatusOK))
+	Expect(w.Header().Get("Content-Length")).To(Equal("7"))
+	Expect(w.Body.Len()).To(Equal(0))
+	Expect(errors).To(Receive(BeNil()))
+}
+
+func (s *InterfaceSuite) TestConvertHeadFailure(t *testing.T) {
+	errors := make(chan error, 1)
+
+	handler := Convert(func(r *http.Request) Response {
+		resp := JSON(func() {})
+		resp.AddCallback(func(err error) { errors <- err })
+		return resp
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("HEAD", "/", nil))
+	Expect(w.Code).To(Equal(http.StatusInternalServerError))
+
+	var err error
+	Expect(errors).To(Receive(&err))
+	Expect(err).To(MatchError(ContainSubstring("unsupported type")))
+}
+
+func (s *InterfaceSuite) TestConvertHeadStream(t *testing.T) {
+	var (
+		reader   = &closer{bytes.NewReader([]byte("content")), false}
+		progress = make(chan int)
+		errors   = make(chan error, 1)
+	)
+
+	handler := Convert(func(r *http.Request) Response {
+		resp := Sniff(Stream(reader, WithProgressChan(progress)))
+		resp.SetHeader("Content-Length", "7")
+		resp.AddCallback(func(err error) { errors <- err })
+		return resp
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("HEAD", "/", nil))
+	Expect(w.Header().Get("Content-Length")).To(Equal("7"))
+	Expect(w.Header().Get("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+	Expect(w.Body.Len()).To(Equal(0))
+	Expect(reader.closed).To(BeTrue())
+	Expect(errors).To(Receive(BeNil()))
+
+	_, ok := <-progress
+	Expect(ok).To(BeFalse())
+}
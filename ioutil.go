@@ -6,6 +6,13 @@ type (
 	// WriterFunc is a function
 	WriterFunc func([]byte) (int, error)
 
+	// readCloser bundles an io.Reader with the Close method of another
+	// value (generally the source of the reader).
+	readCloser struct {
+		io.Reader
+		io.Closer
+	}
+
 	// closeableWriter bundles CloseNotify with an io.Writer.
 	closeableWriter struct {
 		io.Writer
@@ -2,14 +2,14 @@ package response
 
 import (
 	"errors"
+	"testing"
 
-	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
 )
 
 type IOUtilSuite struct{}
 
-func (s *IOUtilSuite) TestWriteAll(t sweet.T) {
+func (s *IOUtilSuite) TestWriteAll(t *testing.T) {
 	var (
 		w    = &slowWriter{}
 		data = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 0}
@@ -21,7 +21,7 @@ func (s *IOUtilSuite) TestWriteAll(t sweet.T) {
 	Expect(w.numCalls).To(Equal(5))
 }
 
-func (s *IOUtilSuite) TestWriteAllError(t sweet.T) {
+func (s *IOUtilSuite) TestWriteAllError(t *testing.T) {
 	var (
 		w    = &failingSlowWriter{}
 		data = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 0}
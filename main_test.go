@@ -1,23 +1,66 @@
 package response
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
-	"github.com/aphistic/sweet"
-	"github.com/aphistic/sweet-junit"
 	. "github.com/onsi/gomega"
 )
 
-func TestMain(m *testing.M) {
-	RegisterFailHandler(sweet.GomegaFail)
+func TestSuites(t *testing.T) {
+	runSuites(t,
+		&InterfaceSuite{},
+		&ImplementationSuite{},
+		&BaseSuite{},
+		&StreamSuite{},
+		&IOUtilSuite{},
+		&DigestSuite{},
+		&StructuredSuite{},
+		&SignatureSuite{},
+		&CSPSuite{},
+		&SecuritySuite{},
+		&CORSSuite{},
+		&HeaderSuite{},
+		&CookieSuite{},
+		&RedirectSuite{},
+		&TemplateSuite{},
+		&FileSuite{},
+		&ArchiveSuite{},
+		&DispositionSuite{},
+		&SniffSuite{},
+		&RouterSuite{},
+		&ProblemSuite{},
+		&TypedSuite{},
+		&SchemaSuite{},
+		&OpenAPISuite{},
+		&ContractSuite{},
+		&DiffSuite{},
+		&ShadowSuite{},
+		&FaultSuite{},
+		&DumpSuite{},
+	)
+}
+
+// runSuites runs the methods of each suite whose names begin with Test as
+// subtests named after the suite and the method.
+func runSuites(t *testing.T, suites ...interface{}) {
+	for _, suite := range suites {
+		value := reflect.ValueOf(suite)
 
-	sweet.Run(m, func(s *sweet.S) {
-		s.RegisterPlugin(junit.NewPlugin())
+		t.Run(value.Elem().Type().Name(), func(t *testing.T) {
+			for i := 0; i < value.NumMethod(); i++ {
+				name := value.Type().Method(i).Name
+				test, ok := value.Method(i).Interface().(func(*testing.T))
+				if !ok || !strings.HasPrefix(name, "Test") {
+					continue
+				}
 
-		s.AddSuite(&InterfaceSuite{})
-		s.AddSuite(&ImplementationSuite{})
-		s.AddSuite(&BaseSuite{})
-		s.AddSuite(&StreamSuite{})
-		s.AddSuite(&IOUtilSuite{})
-	})
+				t.Run(name, func(t *testing.T) {
+					RegisterTestingT(t)
+					test(t)
+				})
+			}
+		})
+	}
 }
@@ -0,0 +1,360 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type (
+	// operation records the metadata of a typed handler registered by Route.
+	operation struct {
+		method  string
+		pattern string
+		input   reflect.Type
+		output  reflect.Type
+		config  *routeConfig
+	}
+
+	routeConfig struct {
+		id        string
+		summary   string
+		tags      []string
+		typed     []TypedConfigFunc
+		responses []documentedResponse
+		headers   map[int][]string
+	}
+
+	// documentedResponse is an additional response declared via WithResponse.
+	documentedResponse struct {
+		statusCode  int
+		contentType string
+		body        reflect.Type
+	}
+
+	// RouteConfigFunc is a function used to configure the Route function.
+	RouteConfigFunc func(*routeConfig)
+
+	openAPIConfig struct {
+		title       string
+		version     string
+		description string
+		servers     []string
+	}
+
+	// OpenAPIConfigFunc is a function used to configure the OpenAPI document.
+	OpenAPIConfigFunc func(*openAPIConfig)
+)
+
+var (
+	problemType   = reflect.TypeOf(Problem{})
+	responseType  = reflect.TypeOf((*Response)(nil)).Elem()
+	validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+)
+
+// WithOperationID sets the unique identifier of the operation.
+func WithOperationID(id string) RouteConfigFunc {
+	return func(c *routeConfig) { c.id = id }
+}
+
+// WithSummary sets a short description of the operation.
+func WithSummary(summary string) RouteConfigFunc {
+	return func(c *routeConfig) { c.summary = summary }
+}
+
+// WithTags adds tags used to group the operation in documentation.
+func WithTags(tags ...string) RouteConfigFunc {
+	return func(c *routeConfig) { c.tags = append(c.tags, tags...) }
+}
+
+// WithTypedConfig passes the given config functions to the Typed adapter
+// of the route. The success status is reflected in the document.
+func WithTypedConfig(configs ...TypedConfigFunc) RouteConfigFunc {
+	return func(c *routeConfig) { c.typed = append(c.typed, configs...) }
+}
+
+// WithResponse documents an additional response of the operation (such as
+// a *Problem returned by the handler). The schema of the body is reflected
+// from the type of the given value (or its element type, if a pointer). An
+// empty content type documents a response without a body.
+func WithResponse(statusCode int, contentType string, body interface{}) RouteConfigFunc {
+	var t reflect.Type
+	if body != nil {
+		t = derefType(reflect.TypeOf(body))
+	}
+
+	return func(c *routeConfig) {
+		c.responses = append(c.responses, documentedResponse{statusCode, contentType, t})
+	}
+}
+
+// WithResponseHeaders documents headers which are always present on the
+// response of the operation with the given status code.
+func WithResponseHeaders(statusCode int, names ...string) RouteConfigFunc {
+	return func(c *routeConfig) {
+		if c.headers == nil {
+			c.headers = map[int][]string{}
+		}
+
+		c.headers[statusCode] = append(c.headers[statusCode], names...)
+	}
+}
+
+// WithAPIInfo sets the title and version of the API. The defaults are
+// API and 0.0.0.
+func WithAPIInfo(title, version string) OpenAPIConfigFunc {
+	return func(c *openAPIConfig) { c.title, c.version = title, version }
+}
+
+// WithAPIDescription sets the description of the API.
+func WithAPIDescription(description string) OpenAPIConfigFunc {
+	return func(c *openAPIConfig) { c.description = description }
+}
+
+// WithAPIServers sets the base URLs at which the API is served.
+func WithAPIServers(urls ...string) OpenAPIConfigFunc {
+	return func(c *openAPIConfig) { c.servers = append(c.servers, urls...) }
+}
+
+// Route registers a typed handler (see Typed) with the router for the given
+// method and pattern, and records its input and output types so that the
+// operation is described by the document generated by OpenAPI.
+func Route[In, Out any](router *Router, method, pattern string, f func(context.Context, In) (Out, error), configs ...RouteConfigFunc) {
+	config := &routeConfig{}
+	for _, f := range configs {
+		f(config)
+	}
+
+	router.Handle(method, pattern, Typed(f, config.typed...))
+
+	router.table.operations = append(router.table.operations, &operation{
+		method:  method,
+		pattern: router.prefix + pattern,
+		input:   reflect.TypeOf((*In)(nil)).Elem(),
+		output:  reflect.TypeOf((*Out)(nil)).Elem(),
+		config:  config,
+	})
+}
+
+// OpenAPI generates an OpenAPI 3.1 document describing the operations
+// registered with the router via Route. An error is returned if an input
+// or output type cannot be described.
+//
+// Struct fields without the omitempty (or omitzero) option are listed as
+// required properties. The same schema describes a type whether it is used
+// in a request or a response body. Typed does not enforce the presence of
+// required properties in request bodies (see Validator), so the document
+// describes the requests clients must send rather than those the server
+// rejects.
+func OpenAPI(router *Router, configs ...OpenAPIConfigFunc) ([]byte, error) {
+	config := &openAPIConfig{
+		title:   "API",
+		version: "0.0.0",
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	registry := newSchemaRegistry()
+	paths := map[string]map[string]interface{}{}
+
+	for _, op := range router.table.operations {
+		path := strings.ReplaceAll(op.pattern, "...}", "}")
+		if _, ok := paths[path]; !ok {
+			paths[path] = map[string]interface{}{}
+		}
+
+		item, err := op.describe(registry)
+		if err != nil {
+			return nil, err
+		}
+
+		paths[path][strings.ToLower(op.method)] = item
+	}
+
+	info := map[string]interface{}{"title": config.title, "version": config.version}
+	if config.description != "" {
+		info["description"] = config.description
+	}
+
+	document := map[string]interface{}{
+		"openapi": "3.1.0",
+		"info":    info,
+		"paths":   paths,
+	}
+
+	if len(registry.schemas) > 0 {
+		document["components"] = map[string]interface{}{"schemas": registry.schemas}
+	}
+
+	if len(config.servers) > 0 {
+		servers := []map[string]string{}
+		for _, url := range config.servers {
+			servers = append(servers, map[string]string{"url": url})
+		}
+
+		document["servers"] = servers
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// OpenAPIHandler creates a handler which serves the document generated by
+// OpenAPI. The document is generated on each request so that it reflects
+// routes registered after the handler is created.
+func OpenAPIHandler(router *Router, configs ...OpenAPIConfigFunc) HandlerFunc {
+	return func(r *http.Request) Response {
+		document, err := OpenAPI(router, configs...)
+		if err != nil {
+			return failure(err)
+		}
+
+		resp := Respond(document)
+		resp.SetHeader("Content-Type", "application/json")
+		return resp
+	}
+}
+
+// describe returns the operation object of the operation.
+func (op *operation) describe(registry *schemaRegistry) (map[string]interface{}, error) {
+	item := map[string]interface{}{}
+	if op.config.id != "" {
+		item["operationId"] = op.config.id
+	}
+
+	if op.config.summary != "" {
+		item["summary"] = op.config.summary
+	}
+
+	if len(op.config.tags) > 0 {
+		item["tags"] = op.config.tags
+	}
+
+	if parameters := pathParameters(op.pattern); len(parameters) > 0 {
+		item["parameters"] = parameters
+	}
+
+	responses := map[string]interface{}{}
+	statusCode := newTypedConfig(op.config.typed).statusCode
+
+	if statusCode == http.StatusNoContent || op.output.Implements(responseType) {
+		responses[strconv.Itoa(statusCode)] = describeResponse(statusCode, "", nil)
+	} else {
+		schema, err := registry.schema(op.output)
+		if err != nil {
+			return nil, err
+		}
+
+		responses[strconv.Itoa(statusCode)] = describeResponse(statusCode, "application/json", schema)
+	}
+
+	rejections := []int{}
+
+	if !isEmptyStruct(op.input) {
+		schema, err := registry.schema(op.input)
+		if err != nil {
+			return nil, err
+		}
+
+		item["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}},
+		}
+
+		rejections = append(rejections,
+			http.StatusBadRequest,
+			http.StatusRequestEntityTooLarge,
+			http.StatusUnsupportedMediaType,
+			http.StatusUnprocessableEntity,
+		)
+	} else if reflect.PointerTo(op.input).Implements(validatorType) {
+		rejections = append(rejections, http.StatusUnprocessableEntity)
+	}
+
+	for _, code := range rejections {
+		schema, err := registry.schema(problemType)
+		if err != nil {
+			return nil, err
+		}
+
+		responses[strconv.Itoa(code)] = describeResponse(code, "application/problem+json", schema)
+	}
+
+	for _, r := range op.config.responses {
+		var schema map[string]interface{}
+		if r.contentType != "" {
+			schema = map[string]interface{}{}
+
+			if r.body != nil {
+				s, err := registry.schema(r.body)
+				if err != nil {
+					return nil, err
+				}
+
+				schema = s
+			}
+		}
+
+		responses[strconv.Itoa(r.statusCode)] = describeResponse(r.statusCode, r.contentType, schema)
+	}
+
+	for code, names := range op.config.headers {
+		key := strconv.Itoa(code)
+		if _, ok := responses[key]; !ok {
+			responses[key] = describeResponse(code, "", nil)
+		}
+
+		headers := map[string]interface{}{}
+		for _, name := range names {
+			headers[http.CanonicalHeaderKey(name)] = map[string]interface{}{
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			}
+		}
+
+		responses[key].(map[string]interface{})["headers"] = headers
+	}
+
+	item["responses"] = responses
+	return item, nil
+}
+
+// describeResponse returns a response object with the given content type
+// and schema. The content is omitted if the content type is empty.
+func describeResponse(statusCode int, contentType string, schema map[string]interface{}) map[string]interface{} {
+	response := map[string]interface{}{"description": http.StatusText(statusCode)}
+	if contentType != "" {
+		response["content"] = map[string]interface{}{contentType: map[string]interface{}{"schema": schema}}
+	}
+
+	return response
+}
+
+// pathParameters returns parameter objects for the parameters and wildcard
+// of the given pattern.
+func pathParameters(pattern string) []map[string]interface{} {
+	segments, _ := parsePattern(pattern)
+
+	parameters := []map[string]interface{}{}
+	for _, s := range segments {
+		if s.kind != segmentLiteral {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     s.value,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+
+	return parameters
+}
+
+// isEmptyStruct returns true if the type is a struct without fields, which
+// is used as the input type of operations without a request body.
+func isEmptyStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 0
+}
@@ -0,0 +1,162 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type OpenAPISuite struct{}
+
+type (
+	openAPIWidget struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	openAPICreateWidget struct {
+		Name string `json:"name"`
+	}
+
+	openAPIQuery struct{}
+)
+
+func (q openAPIQuery) Validate() error { return nil }
+
+func openAPIRouter() *Router {
+	router := NewRouter()
+	api := router.Group("/api")
+
+	Route(api, "GET", "/widgets/{id}", func(ctx context.Context, in struct{}) (openAPIWidget, error) {
+		return openAPIWidget{ID: Param(RequestFromContext(ctx), "id")}, nil
+	}, WithOperationID("getWidget"), WithTags("widgets"), WithResponse(http.StatusNotFound, "application/problem+json", &Problem{}))
+
+	Route(api, "POST", "/widgets", func(ctx context.Context, in openAPICreateWidget) (openAPIWidget, error) {
+		return openAPIWidget{ID: "1", Name: in.Name}, nil
+	}, WithSummary("Create a widget"), WithTypedConfig(WithSuccessStatus(http.StatusCreated)), WithResponseHeaders(http.StatusCreated, "location"))
+
+	Route(api, "DELETE", "/widgets/{id}", func(ctx context.Context, in openAPIQuery) (struct{}, error) {
+		return struct{}{}, nil
+	}, WithTypedConfig(WithSuccessStatus(http.StatusNoContent)))
+
+	Route(router, "GET", "/files/{path...}", func(ctx context.Context, in struct{}) (Response, error) {
+		return Text("file"), nil
+	})
+
+	router.Get("/openapi.json", OpenAPIHandler(router, WithAPIInfo("Widgets", "1.2.3"), WithAPIDescription("Widget API"), WithAPIServers("https://api.example.com")))
+	return router
+}
+
+func (s *OpenAPISuite) TestRoutes(t *testing.T) {
+	router := openAPIRouter()
+
+	w := serveRouter(router, "GET", "/api/widgets/7")
+	Expect(w.Code).To(Equal(http.StatusOK))
+	Expect(w.Body.String()).To(MatchJSON(`{"id": "7", "name": ""}`))
+
+	w = httptest.NewRecorder()
+	r := jsonRequest(`{"name": "gear"}`)
+	r.URL.Path = "/api/widgets"
+	router.ServeHTTP(w, r)
+	Expect(w.Code).To(Equal(http.StatusCreated))
+}
+
+func (s *OpenAPISuite) TestDocument(t *testing.T) {
+	w := serveRouter(openAPIRouter(), "GET", "/openapi.json")
+	Expect(w.Code).To(Equal(http.StatusOK))
+	Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+	Expect(w.Body.String()).To(MatchJSON(`{
+		"openapi": "3.1.0",
+		"info": {"title": "Widgets", "version": "1.2.3", "description": "Widget API"},
+		"servers": [{"url": "https://api.example.com"}],
+		"paths": {
+			"/api/widgets/{id}": {
+				"get": {
+					"operationId": "getWidget",
+					"tags": ["widgets"],
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {
+						"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/openAPIWidget"}}}},
+						"404": {"description": "Not Found", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}}
+					}
+				},
+				"delete": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {
+						"204": {"description": "No Content"},
+						"422": {"description": "Unprocessable Entity", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}}
+					}
+				}
+			},
+			"/api/widgets": {
+				"post": {
+					"summary": "Create a widget",
+					"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/openAPICreateWidget"}}}},
+					"responses": {
+						"201": {
+							"description": "Created",
+							"content": {"application/json": {"schema": {"$ref": "#/components/schemas/openAPIWidget"}}},
+							"headers": {"Location": {"required": true, "schema": {"type": "string"}}}
+						},
+						"400": {"description": "Bad Request", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}},
+						"413": {"description": "Request Entity Too Large", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}},
+						"415": {"description": "Unsupported Media Type", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}},
+						"422": {"description": "Unprocessable Entity", "content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}}
+					}
+				}
+			},
+			"/files/{path}": {
+				"get": {
+					"parameters": [{"name": "path", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"openAPIWidget": {
+					"type": "object",
+					"properties": {"id": {"type": "string"}, "name": {"type": "string"}},
+					"required": ["id", "name"]
+				},
+				"openAPICreateWidget": {
+					"type": "object",
+					"properties": {"name": {"type": "string"}},
+					"required": ["name"]
+				},
+				"Problem": {
+					"type": "object",
+					"properties": {
+						"type": {"type": "string"},
+						"title": {"type": "string"},
+						"status": {"type": "integer"},
+						"detail": {"type": "string"},
+						"instance": {"type": "string"}
+					}
+				}
+			}
+		}
+	}`))
+}
+
+func (s *OpenAPISuite) TestUnsupportedType(t *testing.T) {
+	router := NewRouter()
+	Route(router, "GET", "/", func(ctx context.Context, in struct{}) (chan int, error) { return nil, nil })
+
+	_, err := OpenAPI(router)
+	Expect(err).To(MatchError("unsupported type chan int"))
+
+	resp := OpenAPIHandler(router)(httptest.NewRequest("GET", "/", nil))
+	Expect(resp.StatusCode()).To(Equal(http.StatusInternalServerError))
+}
+
+func (s *OpenAPISuite) TestDefaults(t *testing.T) {
+	data, err := OpenAPI(NewRouter())
+	Expect(err).To(BeNil())
+	Expect(json.Valid(data)).To(BeTrue())
+	Expect(data).To(MatchJSON(`{"openapi": "3.1.0", "info": {"title": "API", "version": "0.0.0"}, "paths": {}}`))
+}
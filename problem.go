@@ -0,0 +1,55 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Problem describes an error returned to the client in the format of
+// RFC 9457. A Problem is also an error, so that handlers may return one
+// to control the response sent for a failure.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// NewProblem creates a problem with the given status code and detail. The
+// title is the standard text of the status code.
+func NewProblem(statusCode int, format string, args ...interface{}) *Problem {
+	if len(args) > 0 {
+		format = fmt.Sprintf(format, args...)
+	}
+
+	return &Problem{
+		Title:  http.StatusText(statusCode),
+		Status: statusCode,
+		Detail: format,
+	}
+}
+
+// Error returns a description of the problem.
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+
+	return fmt.Sprintf("%s: %s", p.Title, p.Detail)
+}
+
+// ProblemJSON creates a response with the problem serialized as JSON for
+// the body. The status code of the response is taken from the problem and
+// defaults to 500.
+func ProblemJSON(problem *Problem) Response {
+	statusCode := problem.Status
+	if statusCode == 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	resp := JSON(problem)
+	resp.SetStatusCode(statusCode)
+	resp.SetHeader("Content-Type", "application/problem+json")
+	return resp
+}
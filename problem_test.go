@@ -0,0 +1,36 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type ProblemSuite struct{}
+
+func (s *ProblemSuite) TestProblemJSON(t *testing.T) {
+	resp := ProblemJSON(NewProblem(http.StatusConflict, "user %s already exists", "bob"))
+	Expect(resp.StatusCode()).To(Equal(http.StatusConflict))
+	Expect(resp.Header("Content-Type")).To(Equal("application/problem+json"))
+
+	_, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(body).To(MatchJSON(`{"title": "Conflict", "status": 409, "detail": "user bob already exists"}`))
+}
+
+func (s *ProblemSuite) TestProblemJSONDefaultStatus(t *testing.T) {
+	resp := ProblemJSON(&Problem{Type: "https://example.com/errors/oops"})
+	Expect(resp.StatusCode()).To(Equal(http.StatusInternalServerError))
+}
+
+func (s *ProblemSuite) TestError(t *testing.T) {
+	var err error = NewProblem(http.StatusNotFound, "no such user")
+	Expect(err).To(MatchError("Not Found: no such user"))
+	Expect(NewProblem(http.StatusNotFound, "")).To(MatchError("Not Found"))
+
+	var problem *Problem
+	Expect(errors.As(err, &problem)).To(BeTrue())
+	Expect(problem.Status).To(Equal(http.StatusNotFound))
+}
@@ -0,0 +1,164 @@
+package response
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type (
+	redirectConfig struct {
+		allowedHosts []string
+		fallback     string
+	}
+
+	// RedirectConfigFunc is a function used to configure the Redirect
+	// constructors.
+	RedirectConfigFunc func(*redirectConfig)
+)
+
+// WithAllowedRedirectHosts allows redirects to the given foreign hosts. A
+// host is either an exact value (example.com) or a wildcard which matches
+// any subdomain (*.example.com). Redirects to the host of the request are
+// always allowed.
+func WithAllowedRedirectHosts(hosts ...string) RedirectConfigFunc {
+	return func(c *redirectConfig) { c.allowedHosts = append(c.allowedHosts, hosts...) }
+}
+
+// WithRedirectFallback sets the target used in place of a target which is
+// malformed or refers to a foreign host which is not allowed. The default
+// fallback is the root path.
+func WithRedirectFallback(target string) RedirectConfigFunc {
+	return func(c *redirectConfig) { c.fallback = target }
+}
+
+// Redirect creates a response which redirects the client to the target.
+// Relative targets are resolved against the path of the request. Targets
+// with a host other than the host of the request (including scheme-relative
+// targets such as //example.com) are replaced by the fallback target unless
+// the host is explicitly allowed. Responses to GET and HEAD requests include
+// a short HTML body for clients which do not follow redirects. This function
+// panics if the status code is not one of 301, 302, 303, 307, or 308.
+func Redirect(r *http.Request, target string, statusCode int, configs ...RedirectConfigFunc) Response {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		panic(fmt.Sprintf("invalid redirect status code %d", statusCode))
+	}
+
+	config := &redirectConfig{fallback: "/"}
+	for _, f := range configs {
+		f(config)
+	}
+
+	location := config.resolve(r, target)
+
+	var resp Response
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		body := fmt.Sprintf("<a href=\"%s\">%s</a>.\n", html.EscapeString(location), http.StatusText(statusCode))
+		resp = Respond([]byte(body))
+		resp.SetHeader("Content-Type", "text/html; charset=utf-8")
+	} else {
+		resp = Empty(statusCode)
+	}
+
+	resp.SetStatusCode(statusCode)
+	resp.SetHeader("Location", location)
+	return resp
+}
+
+// MovedPermanently creates a 301 redirect. Clients may change the method
+// of the redirected request from POST to GET. Use PermanentRedirect to
+// preserve the method.
+func MovedPermanently(r *http.Request, target string, configs ...RedirectConfigFunc) Response {
+	return Redirect(r, target, http.StatusMovedPermanently, configs...)
+}
+
+// Found creates a 302 redirect. Clients may change the method of the
+// redirected request from POST to GET. Use TemporaryRedirect to preserve
+// the method.
+func Found(r *http.Request, target string, configs ...RedirectConfigFunc) Response {
+	return Redirect(r, target, http.StatusFound, configs...)
+}
+
+// SeeOther creates a 303 redirect. Clients always follow this redirect
+// with a GET request, which makes it suitable for responding to a form
+// submission.
+func SeeOther(r *http.Request, target string, configs ...RedirectConfigFunc) Response {
+	return Redirect(r, target, http.StatusSeeOther, configs...)
+}
+
+// TemporaryRedirect creates a 307 redirect. Clients repeat the request
+// with the same method and body at the new target.
+func TemporaryRedirect(r *http.Request, target string, configs ...RedirectConfigFunc) Response {
+	return Redirect(r, target, http.StatusTemporaryRedirect, configs...)
+}
+
+// PermanentRedirect creates a 308 redirect. Clients repeat the request
+// with the same method and body at the new target and may cache the
+// redirect.
+func PermanentRedirect(r *http.Request, target string, configs ...RedirectConfigFunc) Response {
+	return Redirect(r, target, http.StatusPermanentRedirect, configs...)
+}
+
+// resolve returns the value of the Location header for the given target.
+func (c *redirectConfig) resolve(r *http.Request, target string) string {
+	// Browsers treat backslashes as forward slashes, so /\example.com
+	// would otherwise sneak past as a path.
+	u, err := url.Parse(strings.Replace(target, `\`, "/", -1))
+	if err != nil {
+		return c.fallback
+	}
+
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return c.fallback
+	}
+
+	if u.Host != "" || u.Scheme != "" {
+		if !c.allowHost(r, u.Hostname()) {
+			return c.fallback
+		}
+
+		return u.String()
+	}
+
+	base := &url.URL{Path: r.URL.Path}
+	if base.Path == "" {
+		base.Path = "/"
+	}
+
+	// A path which begins with multiple slashes (e.g. ///example.com or
+	// /.//example.com once resolved) is treated as scheme-relative
+	resolved := base.ResolveReference(u)
+	if strings.HasPrefix(resolved.Path, "//") || strings.HasPrefix(resolved.Path, `/\`) {
+		return c.fallback
+	}
+
+	return resolved.String()
+}
+
+// allowHost returns true if the host is the host of the request or
+// matches an allowed host.
+func (c *redirectConfig) allowHost(r *http.Request, host string) bool {
+	if host == "" {
+		return false
+	}
+
+	if strings.EqualFold(host, (&url.URL{Host: r.Host}).Hostname()) {
+		return true
+	}
+
+	for _, allowed := range c.allowedHosts {
+		if strings.HasPrefix(allowed, "*.") {
+			if suffix := allowed[1:]; len(host) > len(suffix) && strings.HasSuffix(strings.ToLower(host), strings.ToLower(suffix)) {
+				return true
+			}
+		} else if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
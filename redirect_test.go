@@ -0,0 +1,82 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type RedirectSuite struct{}
+
+func (s *RedirectSuite) TestRedirect(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://example.com/users/42/edit", nil)
+
+	resp := Found(r, "../43/edit?tab=profile")
+	Expect(resp.StatusCode()).To(Equal(http.StatusFound))
+	Expect(resp.Header("Location")).To(Equal("/users/43/edit?tab=profile"))
+
+	headers, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+	Expect(string(body)).To(Equal("<a href=\"/users/43/edit?tab=profile\">Found</a>.\n"))
+}
+
+func (s *RedirectSuite) TestRedirectNonGet(t *testing.T) {
+	r := httptest.NewRequest("POST", "http://example.com/form", nil)
+
+	resp := SeeOther(r, "/done")
+	Expect(resp.StatusCode()).To(Equal(http.StatusSeeOther))
+	Expect(resp.Header("Location")).To(Equal("/done"))
+
+	headers, body, _ := Serialize(resp)
+	Expect(body).To(BeEmpty())
+	Expect(headers.Get("Content-Length")).To(Equal("0"))
+	Expect(headers.Get("Content-Type")).To(BeEmpty())
+}
+
+func (s *RedirectSuite) TestRedirectCodes(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+
+	Expect(MovedPermanently(r, "/a").StatusCode()).To(Equal(http.StatusMovedPermanently))
+	Expect(Found(r, "/a").StatusCode()).To(Equal(http.StatusFound))
+	Expect(SeeOther(r, "/a").StatusCode()).To(Equal(http.StatusSeeOther))
+	Expect(TemporaryRedirect(r, "/a").StatusCode()).To(Equal(http.StatusTemporaryRedirect))
+	Expect(PermanentRedirect(r, "/a").StatusCode()).To(Equal(http.StatusPermanentRedirect))
+	Expect(func() { Redirect(r, "/a", http.StatusOK) }).To(Panic())
+	Expect(func() { Redirect(r, "/a", http.StatusNotModified) }).To(Panic())
+}
+
+func (s *RedirectSuite) TestRedirectOpenRedirect(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://example.com:8080/login", nil)
+
+	for target, expected := range map[string]string{
+		"https://example.com/home":   "https://example.com/home",
+		"https://evil.com/phish":     "/",
+		"//evil.com/phish":           "/",
+		`/\evil.com/phish`:           "/",
+		`\\evil.com`:                 "/",
+		"///evil.com":                "/",
+		`/\/evil.com`:                "/",
+		"/.//evil.com":               "/",
+		"javascript:alert(1)":        "/",
+		"https://api.partner.com/cb": "https://api.partner.com/cb",
+		"https://partner.com/cb":     "/",
+		"https://trusted.org/cb":     "https://trusted.org/cb",
+		"http://[::1":                "/",
+	} {
+		resp := Found(r, target, WithAllowedRedirectHosts("*.partner.com", "trusted.org"))
+		Expect(resp.Header("Location")).To(Equal(expected), target)
+	}
+
+	resp := Found(r, "https://evil.com", WithRedirectFallback("/home"))
+	Expect(resp.Header("Location")).To(Equal("/home"))
+}
+
+func (s *RedirectSuite) TestRedirectEscapesBody(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+
+	_, body, _ := Serialize(Found(r, `/search?q="><script>`))
+	Expect(string(body)).NotTo(ContainSubstring("<script>"))
+}
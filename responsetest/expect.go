@@ -0,0 +1,118 @@
+package responsetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/efritz/response"
+)
+
+// Assertion makes assertions about a recorded response. Failed assertions
+// are reported to the test and do not stop the chain, so every assertion
+// of a chain is evaluated.
+type Assertion struct {
+	t        testing.TB
+	recorder *Recorder
+}
+
+// ExpectResponse writes the response to a new recorder and returns an
+// assertion about the recorded response.
+func ExpectResponse(t testing.TB, resp response.Response, configs ...RecorderConfigFunc) *Assertion {
+	return &Assertion{t: t, recorder: Record(resp, configs...)}
+}
+
+// ExpectHandler invokes the handler with the given request and returns an
+// assertion about the recorded response.
+func ExpectHandler(t testing.TB, handler response.HandlerFunc, req *http.Request, configs ...RecorderConfigFunc) *Assertion {
+	return &Assertion{t: t, recorder: Serve(handler, req, configs...)}
+}
+
+// Recorder returns the recorder holding the response.
+func (a *Assertion) Recorder() *Recorder {
+	return a.recorder
+}
+
+// ExpectStatus asserts that the response has the given status code.
+func (a *Assertion) ExpectStatus(statusCode int) *Assertion {
+	a.t.Helper()
+
+	if actual := a.recorder.Code; actual != statusCode {
+		a.t.Errorf("expected status %d, got %d", statusCode, actual)
+	}
+
+	return a
+}
+
+// ExpectHeader asserts that the first value of the header equals the given
+// value. An empty value asserts that the header is absent.
+func (a *Assertion) ExpectHeader(name, value string) *Assertion {
+	a.t.Helper()
+
+	if actual := a.recorder.Header().Get(name); actual != value {
+		a.t.Errorf("expected header %s to be %q, got %q", name, value, actual)
+	}
+
+	return a
+}
+
+// ExpectJSON asserts that the body is JSON which is structurally equal to
+// the expected value. The expected value may be a string or byte slice of
+// JSON, or any value which is serialized to JSON before comparison.
+func (a *Assertion) ExpectJSON(expected interface{}) *Assertion {
+	a.t.Helper()
+
+	var expectedData []byte
+	switch e := expected.(type) {
+	case string:
+		expectedData = []byte(e)
+	case []byte:
+		expectedData = e
+	default:
+		data, err := json.Marshal(expected)
+		if err != nil {
+			a.t.Errorf("failed to serialize expected value: %s", err)
+			return a
+		}
+
+		expectedData = data
+	}
+
+	var expectedValue, actualValue interface{}
+	if err := json.Unmarshal(expectedData, &expectedValue); err != nil {
+		a.t.Errorf("expected value is not valid JSON: %s", err)
+		return a
+	}
+
+	body := a.recorder.Body.Bytes()
+	if err := json.Unmarshal(body, &actualValue); err != nil {
+		a.t.Errorf("expected body to be JSON, got %q", body)
+		return a
+	}
+
+	if !reflect.DeepEqual(expectedValue, actualValue) {
+		a.t.Errorf("expected body to be JSON equal to %s, got %s", bytes.TrimSpace(expectedData), bytes.TrimSpace(body))
+	}
+
+	return a
+}
+
+// ExpectBodyMatches asserts that the body matches the regular expression.
+func (a *Assertion) ExpectBodyMatches(pattern string) *Assertion {
+	a.t.Helper()
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		a.t.Errorf("invalid pattern %q: %s", pattern, err)
+		return a
+	}
+
+	if body := a.recorder.Body.Bytes(); !re.Match(body) {
+		a.t.Errorf("expected body to match %q, got %q", pattern, body)
+	}
+
+	return a
+}
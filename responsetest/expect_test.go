@@ -0,0 +1,95 @@
+package responsetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/efritz/response"
+	. "github.com/onsi/gomega"
+)
+
+type ExpectSuite struct{}
+
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (s *ExpectSuite) TestPassing(t *testing.T) {
+	ft := &fakeT{}
+	resp := response.JSON(map[string]interface{}{"name": "bob", "tags": []string{"a"}}).SetStatusCode(http.StatusCreated)
+
+	ExpectResponse(ft, resp).
+		ExpectStatus(http.StatusCreated).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectHeader("X-Missing", "").
+		ExpectJSON(`{"tags": ["a"], "name": "bob"}`).
+		ExpectJSON(map[string]interface{}{"name": "bob", "tags": []string{"a"}}).
+		ExpectBodyMatches(`"name":\s*"b.b"`)
+
+	Expect(ft.errors).To(BeEmpty())
+}
+
+func (s *ExpectSuite) TestFailing(t *testing.T) {
+	ft := &fakeT{}
+
+	ExpectResponse(ft, response.Text("hello")).
+		ExpectStatus(http.StatusNotFound).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectJSON(`{}`).
+		ExpectBodyMatches(`^bye`).
+		ExpectBodyMatches(`(`)
+
+	Expect(ft.errors).To(Equal([]string{
+		"expected status 404, got 200",
+		`expected header Content-Type to be "application/json", got "text/plain; charset=utf-8"`,
+		`expected body to be JSON, got "hello"`,
+		`expected body to match "^bye", got "hello"`,
+		"invalid pattern \"(\": error parsing regexp: missing closing ): `(`",
+	}))
+}
+
+func (s *ExpectSuite) TestJSONMismatch(t *testing.T) {
+	ft := &fakeT{}
+
+	ExpectResponse(ft, response.JSON([]int{1, 2})).
+		ExpectJSON([]int{2, 1}).
+		ExpectJSON(`{`).
+		ExpectJSON(make(chan int))
+
+	Expect(ft.errors).To(HaveLen(3))
+	Expect(ft.errors[0]).To(Equal("expected body to be JSON equal to [2,1], got [1,2]"))
+	Expect(ft.errors[1]).To(HavePrefix("expected value is not valid JSON"))
+	Expect(ft.errors[2]).To(HavePrefix("failed to serialize expected value"))
+}
+
+func (s *ExpectSuite) TestHandler(t *testing.T) {
+	ft := &fakeT{}
+
+	handler := func(r *http.Request) response.Response {
+		return response.Text("id=%s", r.URL.Query().Get("id"))
+	}
+
+	a := ExpectHandler(ft, handler, httptest.NewRequest("GET", "/?id=7", nil)).
+		ExpectStatus(http.StatusOK).
+		ExpectBodyMatches(`^id=7$`)
+
+	Expect(ft.errors).To(BeEmpty())
+	Expect(a.Recorder().Chunks()).To(Equal([]int{4}))
+}
+
+func (s *ExpectSuite) TestDisconnect(t *testing.T) {
+	ft := &fakeT{}
+
+	a := ExpectResponse(ft, response.Text("hello world"), WithDisconnectAt(5)).ExpectBodyMatches(`^hello$`)
+	Expect(ft.errors).To(BeEmpty())
+	Expect(a.Recorder().Disconnected()).To(BeTrue())
+}
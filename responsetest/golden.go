@@ -0,0 +1,254 @@
+package responsetest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	goldenConfig struct {
+		dir      string
+		update   bool
+		headers  map[string]struct{}
+		patterns []*regexp.Regexp
+	}
+
+	// GoldenConfigFunc is a function used to configure golden file snapshots.
+	GoldenConfigFunc func(*goldenConfig)
+)
+
+// UpdateEnv is the name of the environment variable which, when set to a
+// true value (e.g. UPDATE_GOLDEN=1 go test ./...), causes golden files to
+// be written instead of compared.
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// WithGoldenDir sets the directory containing golden files. The default
+// directory is testdata.
+func WithGoldenDir(dir string) GoldenConfigFunc {
+	return func(c *goldenConfig) { c.dir = dir }
+}
+
+// WithUpdate sets whether golden files are written instead of compared.
+// This overrides the UpdateEnv environment variable, and can be used to wire
+// golden files to a flag defined by the test package:
+//
+//	var update = flag.Bool("update", false, "update golden files")
+//	...
+//	assertion.ExpectGolden("name", responsetest.WithUpdate(*update))
+func WithUpdate(update bool) GoldenConfigFunc {
+	return func(c *goldenConfig) { c.update = update }
+}
+
+// WithIgnoredHeaders omits the given headers from the snapshot. The Date
+// header is always ignored.
+func WithIgnoredHeaders(names ...string) GoldenConfigFunc {
+	return func(c *goldenConfig) {
+		for _, name := range names {
+			c.headers[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+}
+
+// WithIgnoredHeaderPattern omits headers whose canonical name matches the
+// given regular expression from the snapshot.
+func WithIgnoredHeaderPattern(pattern *regexp.Regexp) GoldenConfigFunc {
+	return func(c *goldenConfig) { c.patterns = append(c.patterns, pattern) }
+}
+
+// ExpectGolden asserts that the snapshot of the response equals the content
+// of the golden file with the given name (and the extension .golden). When
+// updating is enabled (see WithUpdate and UpdateEnv), the golden file is
+// written instead.
+func (a *Assertion) ExpectGolden(name string, configs ...GoldenConfigFunc) *Assertion {
+	a.t.Helper()
+
+	config := newGoldenConfig(configs)
+	actual := snapshot(a.recorder, config)
+	path := filepath.Join(config.dir, name+".golden")
+
+	if config.update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			a.t.Errorf("failed to create golden file directory: %s", err)
+		} else if err := os.WriteFile(path, actual, 0644); err != nil {
+			a.t.Errorf("failed to write golden file: %s", err)
+		}
+
+		return a
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		a.t.Errorf("failed to read golden file (set "+UpdateEnv+"=1 to create it): %s", err)
+		return a
+	}
+
+	if !bytes.Equal(expected, actual) {
+		a.t.Errorf("response does not match golden file %s (set "+UpdateEnv+"=1 to rewrite it)\n--- expected\n%s\n--- actual\n%s", path, expected, actual)
+	}
+
+	return a
+}
+
+// Snapshot returns a deterministic text representation of the recorded
+// response: the status line, the sorted headers, the body (indented if it
+// is JSON or XML), and any trailers.
+func (r *Recorder) Snapshot(configs ...GoldenConfigFunc) []byte {
+	return snapshot(r, newGoldenConfig(configs))
+}
+
+// newGoldenConfig applies the given config functions over defaults.
+func newGoldenConfig(configs []GoldenConfigFunc) *goldenConfig {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+
+	config := &goldenConfig{
+		dir:     "testdata",
+		update:  update,
+		headers: map[string]struct{}{"Date": {}},
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	return config
+}
+
+// snapshot serializes the recorded response.
+func snapshot(r *Recorder, config *goldenConfig) []byte {
+	result := r.Result()
+	buffer := &bytes.Buffer{}
+
+	fmt.Fprintf(buffer, "HTTP/1.1 %d %s\n", result.StatusCode, http.StatusText(result.StatusCode))
+	writeSnapshotHeaders(buffer, result.Header, config)
+
+	if body := r.Body.Bytes(); len(body) > 0 {
+		buffer.WriteString("\n")
+		buffer.Write(formatBody(result.Header.Get("Content-Type"), body))
+
+		if !bytes.HasSuffix(buffer.Bytes(), []byte("\n")) {
+			buffer.WriteString("\n")
+		}
+	}
+
+	if len(result.Trailer) > 0 {
+		buffer.WriteString("\n")
+		writeSnapshotHeaders(buffer, result.Trailer, config)
+	}
+
+	return buffer.Bytes()
+}
+
+// writeSnapshotHeaders writes the headers which are not ignored in order
+// of their canonical names. Values of the same header retain their order.
+func writeSnapshotHeaders(w io.Writer, header http.Header, config *goldenConfig) {
+	names := []string{}
+	for name := range header {
+		if !config.ignored(name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, value)
+		}
+	}
+}
+
+// ignored returns true if the header is omitted from snapshots.
+func (c *goldenConfig) ignored(name string) bool {
+	if _, ok := c.headers[http.CanonicalHeaderKey(name)]; ok {
+		return true
+	}
+
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatBody indents JSON and XML bodies. Other text bodies are returned
+// as-is and binary bodies are encoded as base64.
+func formatBody(contentType string, body []byte) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		indented := &bytes.Buffer{}
+		if err := json.Indent(indented, body, "", "  "); err == nil {
+			return indented.Bytes()
+		}
+
+	case strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml"):
+		if indented, err := indentXML(body); err == nil {
+			return indented
+		}
+	}
+
+	if !utf8.Valid(body) {
+		return []byte(fmt.Sprintf("[%d bytes, base64]\n%s", len(body), base64.StdEncoding.EncodeToString(body)))
+	}
+
+	return body
+}
+
+// indentXML re-encodes the XML document with indentation. Whitespace-only
+// character data between elements is discarded.
+func indentXML(body []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	buffer := &bytes.Buffer{}
+	encoder := xml.NewEncoder(buffer)
+	encoder.Indent("", "  ")
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if data, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return nil, err
+		}
+
+		if _, ok := token.(xml.ProcInst); ok {
+			// The encoder only indents elements
+			if err := encoder.Flush(); err != nil {
+				return nil, err
+			}
+
+			buffer.WriteString("\n")
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
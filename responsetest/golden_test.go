@@ -0,0 +1,125 @@
+package responsetest
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/efritz/response"
+	. "github.com/onsi/gomega"
+)
+
+type GoldenSuite struct{}
+
+func (s *GoldenSuite) TestSnapshotJSON(t *testing.T) {
+	resp := response.JSON(map[string]interface{}{"name": "bob", "tags": []string{"a", "b"}})
+	resp.AddHeader("X-Values", "2")
+	resp.AddHeader("X-Values", "1")
+	resp.SetHeader("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+	resp.SetHeader("X-Request-Id", "abc123")
+	resp.SetTrailer("X-Checksum", "ok")
+
+	Expect(string(Record(resp).Snapshot(WithIgnoredHeaders("x-request-id")))).To(Equal(`HTTP/1.1 200 OK
+Content-Type: application/json
+Trailer: X-Checksum
+X-Values: 2
+X-Values: 1
+
+{
+  "name": "bob",
+  "tags": [
+    "a",
+    "b"
+  ]
+}
+
+X-Checksum: ok
+`))
+}
+
+func (s *GoldenSuite) TestSnapshotXML(t *testing.T) {
+	resp := response.Respond([]byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><g id="a">  <rect/></g></svg>`))
+	resp.SetHeader("Content-Type", "image/svg+xml")
+
+	Expect(string(Record(resp).Snapshot(WithIgnoredHeaderPattern(regexp.MustCompile(`^Content-`))))).To(Equal(`HTTP/1.1 200 OK
+
+<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg">
+  <g id="a">
+    <rect></rect>
+  </g>
+</svg>
+`))
+}
+
+func (s *GoldenSuite) TestSnapshotText(t *testing.T) {
+	Expect(string(Record(response.Text("line 1\nline 2")).Snapshot())).To(Equal("HTTP/1.1 200 OK\nContent-Length: 13\nContent-Type: text/plain; charset=utf-8\n\nline 1\nline 2\n"))
+	Expect(string(Record(response.Respond([]byte{0xff, 0x00})).Snapshot())).To(Equal("HTTP/1.1 200 OK\nContent-Length: 2\n\n[2 bytes, base64]\n/wA=\n"))
+	Expect(string(Record(response.Empty(204)).Snapshot())).To(Equal("HTTP/1.1 204 No Content\nContent-Length: 0\n"))
+}
+
+func (s *GoldenSuite) TestExpectGolden(t *testing.T) {
+	ft := &fakeT{}
+	ExpectResponse(ft, response.JSON(map[string]string{"greeting": "hello"})).ExpectGolden("greeting")
+	Expect(ft.errors).To(BeEmpty())
+
+	ExpectResponse(ft, response.JSON(map[string]string{"greeting": "goodbye"})).ExpectGolden("greeting")
+	Expect(ft.errors).To(HaveLen(1))
+	Expect(ft.errors[0]).To(HavePrefix("response does not match golden file testdata/greeting.golden"))
+}
+
+func (s *GoldenSuite) TestMissingGolden(t *testing.T) {
+	ft := &fakeT{}
+	ExpectResponse(ft, response.Text("hello")).ExpectGolden("missing")
+	Expect(ft.errors).To(HaveLen(1))
+	Expect(ft.errors[0]).To(HavePrefix("failed to read golden file (set UPDATE_GOLDEN=1 to create it)"))
+}
+
+func (s *GoldenSuite) TestUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	ft := &fakeT{}
+	ExpectResponse(ft, response.Text("hello")).ExpectGolden("nested/text", WithGoldenDir(dir), WithUpdate(true))
+	Expect(ft.errors).To(BeEmpty())
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "nested", "text.golden"))
+	Expect(err).To(BeNil())
+	Expect(string(data)).To(Equal("HTTP/1.1 200 OK\nContent-Length: 5\nContent-Type: text/plain; charset=utf-8\n\nhello\n"))
+
+	ExpectResponse(ft, response.Text("hello")).ExpectGolden("nested/text", WithGoldenDir(dir))
+	Expect(ft.errors).To(BeEmpty())
+}
+
+func (s *GoldenSuite) TestUpdateEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	previous, ok := os.LookupEnv(UpdateEnv)
+	Expect(os.Setenv(UpdateEnv, "1")).To(BeNil())
+	defer func() {
+		if ok {
+			os.Setenv(UpdateEnv, previous)
+		} else {
+			os.Unsetenv(UpdateEnv)
+		}
+	}()
+
+	ft := &fakeT{}
+	ExpectResponse(ft, response.Text("hello")).ExpectGolden("text", WithGoldenDir(dir))
+	Expect(ft.errors).To(BeEmpty())
+	Expect(filepath.Join(dir, "text.golden")).To(BeAnExistingFile())
+
+	// An explicit setting takes precedence over the environment
+	ExpectResponse(ft, response.Text("changed")).ExpectGolden("text", WithGoldenDir(dir), WithUpdate(false))
+	Expect(ft.errors).To(HaveLen(1))
+}
+
+func (s *GoldenSuite) TestNoGlobalFlag(t *testing.T) {
+	Expect(flag.Lookup("update")).To(BeNil())
+}
@@ -0,0 +1,40 @@
+package responsetest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSuites(t *testing.T) {
+	runSuites(t,
+		&RecorderSuite{},
+		&ExpectSuite{},
+		&GoldenSuite{},
+	)
+}
+
+// runSuites runs the methods of each suite whose names begin with Test as
+// subtests named after the suite and the method.
+func runSuites(t *testing.T, suites ...interface{}) {
+	for _, suite := range suites {
+		value := reflect.ValueOf(suite)
+
+		t.Run(value.Elem().Type().Name(), func(t *testing.T) {
+			for i := 0; i < value.NumMethod(); i++ {
+				name := value.Type().Method(i).Name
+				test, ok := value.Method(i).Interface().(func(*testing.T))
+				if !ok || !strings.HasPrefix(name, "Test") {
+					continue
+				}
+
+				t.Run(name, func(t *testing.T) {
+					RegisterTestingT(t)
+					test(t)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Package responsetest provides utilities for testing responses and
+// handlers built with the response package.
+//
+// Golden files (see ExpectGolden) are written instead of compared when the
+// UPDATE_GOLDEN environment variable is set to a true value. This package
+// registers no flags; a test package which prefers an -update flag can
+// define one and pass it to each assertion:
+//
+//	var update = flag.Bool("update", false, "update golden files")
+//	...
+//	assertion.ExpectGolden("name", responsetest.WithUpdate(*update))
+//
+// and then run go test -update in that package.
+package responsetest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/efritz/response"
+)
+
+type (
+	// Recorder is an http.ResponseWriter which records the response written
+	// to it, along with the offsets at which the body was flushed and the
+	// size of every write. It implements http.Flusher and http.CloseNotifier
+	// and can simulate a client which disconnects part way through the body.
+	Recorder struct {
+		*httptest.ResponseRecorder
+		flushes      []int
+		chunks       []int
+		written      int
+		disconnectAt int
+		closeCh      chan bool
+	}
+
+	// RecorderConfigFunc is a function used to configure a Recorder.
+	RecorderConfigFunc func(*Recorder)
+)
+
+// ErrDisconnected is returned by writes to a recorder after the simulated
+// client has disconnected.
+var ErrDisconnected = errors.New("client disconnected")
+
+// WithDisconnectAt instructs the recorder to disconnect once the given
+// number of body bytes have been written. The write which crosses the
+// offset is truncated and returns ErrDisconnected.
+func WithDisconnectAt(offset int) RecorderConfigFunc {
+	return func(r *Recorder) { r.disconnectAt = offset }
+}
+
+// NewRecorder creates a new recorder.
+func NewRecorder(configs ...RecorderConfigFunc) *Recorder {
+	r := &Recorder{
+		ResponseRecorder: httptest.NewRecorder(),
+		disconnectAt:     -1,
+		closeCh:          make(chan bool),
+	}
+
+	for _, f := range configs {
+		f(r)
+	}
+
+	if r.disconnectAt == 0 {
+		r.Disconnect()
+	}
+
+	return r
+}
+
+// Record writes the response to a new recorder.
+func Record(resp response.Response, configs ...RecorderConfigFunc) *Recorder {
+	r := NewRecorder(configs...)
+	resp.WriteTo(r)
+	return r
+}
+
+// Serve invokes the handler with the given request and writes the response
+// to a new recorder (as done by response.Convert).
+func Serve(handler response.HandlerFunc, req *http.Request, configs ...RecorderConfigFunc) *Recorder {
+	r := NewRecorder(configs...)
+	response.Convert(handler)(r, req)
+	return r
+}
+
+// Write records the given data as a single chunk of the body.
+func (r *Recorder) Write(data []byte) (int, error) {
+	if r.Disconnected() {
+		return 0, ErrDisconnected
+	}
+
+	var err error
+	if r.disconnectAt >= 0 && r.written+len(data) > r.disconnectAt {
+		data = data[:r.disconnectAt-r.written]
+		err = ErrDisconnected
+	}
+
+	n, writeErr := r.ResponseRecorder.Write(data)
+	if n > 0 {
+		r.written += n
+		r.chunks = append(r.chunks, n)
+	}
+
+	if r.disconnectAt >= 0 && r.written >= r.disconnectAt {
+		r.Disconnect()
+	}
+
+	if err != nil {
+		return n, err
+	}
+
+	return n, writeErr
+}
+
+// WriteString records the given string as a single chunk of the body.
+func (r *Recorder) WriteString(s string) (int, error) {
+	return r.Write([]byte(s))
+}
+
+// Flush records the current length of the body as a flush point.
+func (r *Recorder) Flush() {
+	if !r.Disconnected() {
+		r.flushes = append(r.flushes, r.written)
+		r.ResponseRecorder.Flush()
+	}
+}
+
+// CloseNotify returns a channel which is closed once the simulated client
+// disconnects.
+func (r *Recorder) CloseNotify() <-chan bool {
+	return r.closeCh
+}
+
+// Disconnect simulates a client disconnect. Subsequent writes fail.
+func (r *Recorder) Disconnect() {
+	if !r.Disconnected() {
+		close(r.closeCh)
+	}
+}
+
+// Disconnected returns true if the simulated client has disconnected.
+func (r *Recorder) Disconnected() bool {
+	select {
+	case <-r.closeCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Flushes returns the body offsets at which the response was flushed.
+func (r *Recorder) Flushes() []int {
+	return r.flushes
+}
+
+// Chunks returns the size of each write of body content.
+func (r *Recorder) Chunks() []int {
+	return r.chunks
+}
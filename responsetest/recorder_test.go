@@ -0,0 +1,96 @@
+package responsetest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/efritz/response"
+	. "github.com/onsi/gomega"
+)
+
+type RecorderSuite struct{}
+
+func (s *RecorderSuite) TestRecord(t *testing.T) {
+	r := Record(response.JSON(map[string]string{"a": "b"}).SetStatusCode(http.StatusCreated))
+	Expect(r.Code).To(Equal(http.StatusCreated))
+	Expect(r.Header().Get("Content-Type")).To(Equal("application/json"))
+	Expect(r.Body.String()).To(MatchJSON(`{"a": "b"}`))
+	Expect(r.Chunks()).To(Equal([]int{9}))
+	Expect(r.Flushes()).To(BeEmpty())
+	Expect(r.Disconnected()).To(BeFalse())
+}
+
+func (s *RecorderSuite) TestFlushes(t *testing.T) {
+	r := NewRecorder()
+	r.WriteString("abc")
+	r.Flush()
+	r.Write([]byte("defg"))
+	r.WriteString("h")
+	r.Flush()
+
+	Expect(r.Body.String()).To(Equal("abcdefgh"))
+	Expect(r.Chunks()).To(Equal([]int{3, 4, 1}))
+	Expect(r.Flushes()).To(Equal([]int{3, 8}))
+	Expect(r.Flushed).To(BeTrue())
+}
+
+func (s *RecorderSuite) TestStreamChunks(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 80*1024)
+	r := Record(response.Stream(ioutil.NopCloser(bytes.NewReader(data)), response.WithFlush()))
+
+	Expect(r.Body.Len()).To(Equal(len(data)))
+	Expect(r.Chunks()).To(Equal([]int{32 * 1024, 32 * 1024, 16 * 1024}))
+	Expect(r.Flushes()).To(Equal([]int{32 * 1024, 64 * 1024, 80 * 1024}))
+}
+
+func (s *RecorderSuite) TestDisconnectAt(t *testing.T) {
+	errs := make(chan error, 1)
+	resp := response.Respond([]byte("hello world")).AddCallback(func(err error) { errs <- err })
+
+	r := Record(resp, WithDisconnectAt(5))
+	Expect(r.Body.String()).To(Equal("hello"))
+	Expect(r.Disconnected()).To(BeTrue())
+	Expect(r.CloseNotify()).To(BeClosed())
+	Expect(errs).To(Receive(Equal(ErrDisconnected)))
+}
+
+func (s *RecorderSuite) TestDisconnectAtBoundary(t *testing.T) {
+	r := NewRecorder(WithDisconnectAt(3))
+
+	n, err := r.WriteString("abc")
+	Expect(n).To(Equal(3))
+	Expect(err).To(BeNil())
+	Expect(r.Disconnected()).To(BeTrue())
+
+	n, err = r.WriteString("d")
+	Expect(n).To(Equal(0))
+	Expect(err).To(Equal(ErrDisconnected))
+
+	r.Flush()
+	Expect(r.Flushes()).To(BeEmpty())
+}
+
+func (s *RecorderSuite) TestDisconnectAtStart(t *testing.T) {
+	reader := strings.NewReader(strings.Repeat("x", 1024))
+	r := Record(response.Stream(ioutil.NopCloser(reader)), WithDisconnectAt(0))
+
+	Expect(r.Body.Len()).To(Equal(0))
+	Expect(reader.Len()).To(Equal(1024))
+}
+
+func (s *RecorderSuite) TestServe(t *testing.T) {
+	handler := func(r *http.Request) response.Response {
+		return response.Text("path %s", r.URL.Path)
+	}
+
+	r := Serve(handler, httptest.NewRequest("GET", "/test", nil))
+	Expect(r.Body.String()).To(Equal("path /test"))
+
+	r = Serve(handler, httptest.NewRequest("HEAD", "/test", nil))
+	Expect(r.Header().Get("Content-Length")).To(Equal("10"))
+	Expect(r.Body.Len()).To(Equal(0))
+}
@@ -0,0 +1,381 @@
+package response
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type (
+	// Router dispatches requests to the HandlerFunc registered for the
+	// method and path of the request. Patterns are paths whose segments
+	// may be a parameter ({id}), which matches a single non-empty segment,
+	// or a trailing wildcard ({path...}), which matches the remainder of
+	// the path. Literal segments take precedence over parameters, which
+	// take precedence over wildcards.
+	Router struct {
+		table      *routeTable
+		prefix     string
+		middleware []Middleware
+	}
+
+	routeTable struct {
+		routes     []*route
+		operations []*operation
+		notFound   HandlerFunc
+	}
+
+	route struct {
+		segments   []segment
+		handlers   map[string]HandlerFunc
+		middleware []Middleware
+	}
+
+	segment struct {
+		kind  segmentKind
+		value string
+	}
+
+	segmentKind int
+
+	paramsKey struct{}
+
+	routerConfig struct {
+		notFound HandlerFunc
+	}
+
+	// RouterConfigFunc is a function used to configure NewRouter.
+	RouterConfigFunc func(*routerConfig)
+)
+
+const (
+	segmentLiteral segmentKind = iota
+	segmentParam
+	segmentWildcard
+)
+
+// WithNotFoundHandler sets the handler invoked for requests which do not
+// match the path of any route. The default handler responds with a 404.
+func WithNotFoundHandler(handler HandlerFunc) RouterConfigFunc {
+	return func(c *routerConfig) { c.notFound = handler }
+}
+
+// NewRouter creates an empty router.
+func NewRouter(configs ...RouterConfigFunc) *Router {
+	config := &routerConfig{
+		notFound: func(r *http.Request) Response {
+			return Text("404 page not found").SetStatusCode(http.StatusNotFound)
+		},
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	return &Router{table: &routeTable{notFound: config.notFound}}
+}
+
+// Group creates a router which registers routes with the given path prefix
+// on the same route table. Handlers registered through the group are wrapped
+// with the given middleware, after the middleware of the receiver.
+func (r *Router) Group(prefix string, middleware ...Middleware) *Router {
+	return &Router{
+		table:      r.table,
+		prefix:     r.prefix + prefix,
+		middleware: append(append([]Middleware{}, r.middleware...), middleware...),
+	}
+}
+
+// Handle registers the handler for the given method and pattern. This
+// method panics if the pattern is malformed or if a handler is already
+// registered for the method and pattern.
+func (r *Router) Handle(method, pattern string, handler HandlerFunc) {
+	pattern = r.prefix + pattern
+	segments, err := parsePattern(pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	rt := r.table.find(segments)
+	if rt == nil {
+		rt = &route{
+			segments:   segments,
+			handlers:   map[string]HandlerFunc{},
+			middleware: r.middleware,
+		}
+
+		r.table.routes = append(r.table.routes, rt)
+	} else if !sameParamNames(rt.segments, segments) {
+		panic(fmt.Sprintf("pattern %s conflicts with the parameter names of an existing route", pattern))
+	}
+
+	if _, ok := rt.handlers[method]; ok {
+		panic(fmt.Sprintf("handler already registered for %s %s", method, pattern))
+	}
+
+	rt.handlers[method] = applyMiddleware(handler, r.middleware)
+}
+
+// Get registers the handler for GET requests of the given pattern.
+func (r *Router) Get(pattern string, handler HandlerFunc) {
+	r.Handle(http.MethodGet, pattern, handler)
+}
+
+// Post registers the handler for POST requests of the given pattern.
+func (r *Router) Post(pattern string, handler HandlerFunc) {
+	r.Handle(http.MethodPost, pattern, handler)
+}
+
+// Put registers the handler for PUT requests of the given pattern.
+func (r *Router) Put(pattern string, handler HandlerFunc) {
+	r.Handle(http.MethodPut, pattern, handler)
+}
+
+// Patch registers the handler for PATCH requests of the given pattern.
+func (r *Router) Patch(pattern string, handler HandlerFunc) {
+	r.Handle(http.MethodPatch, pattern, handler)
+}
+
+// Delete registers the handler for DELETE requests of the given pattern.
+func (r *Router) Delete(pattern string, handler HandlerFunc) {
+	r.Handle(http.MethodDelete, pattern, handler)
+}
+
+// Dispatch invokes the handler of the route matching the request. HEAD
+// requests are served by the GET handler of a route unless a HEAD handler
+// is registered. OPTIONS requests to a route without an OPTIONS handler are
+// answered with an empty response listing the allowed methods (after the
+// middleware of the route is applied, so that CORS preflight requests are
+// handled). Requests with a method not registered for the matching route are
+// answered with a 405.
+func (r *Router) Dispatch(req *http.Request) Response {
+	rt, params := r.table.match(req.URL)
+	if rt == nil {
+		return r.table.notFound(req)
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), paramsKey{}, params))
+
+	if handler, ok := rt.handler(req.Method); ok {
+		return handler(req)
+	}
+
+	allow := strings.Join(rt.allowed(), ", ")
+
+	if req.Method == http.MethodOptions {
+		return applyMiddleware(func(r *http.Request) Response {
+			return Empty(http.StatusNoContent).SetHeader("Allow", allow)
+		}, rt.middleware)(req)
+	}
+
+	resp := Text("405 method not allowed").SetStatusCode(http.StatusMethodNotAllowed)
+	return resp.SetHeader("Allow", allow)
+}
+
+// ServeHTTP dispatches the request and writes the response.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	Convert(r.Dispatch)(w, req)
+}
+
+// Param returns the value of the path parameter with the given name captured
+// by the route matching the request, or an empty string.
+func Param(r *http.Request, name string) string {
+	if params, ok := r.Context().Value(paramsKey{}).(map[string]string); ok {
+		return params[name]
+	}
+
+	return ""
+}
+
+// applyMiddleware wraps the handler so that the first middleware is the
+// outermost.
+func applyMiddleware(handler HandlerFunc, middleware []Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
+
+// parsePattern splits the pattern into its segments.
+func parsePattern(pattern string) ([]segment, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("pattern %q must begin with /", pattern)
+	}
+
+	parts := strings.Split(pattern[1:], "/")
+	segments := make([]segment, 0, len(parts))
+	names := map[string]struct{}{}
+
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			if strings.ContainsAny(part, "{}") {
+				return nil, fmt.Errorf("pattern %q has malformed segment %q", pattern, part)
+			}
+
+			segments = append(segments, segment{segmentLiteral, part})
+			continue
+		}
+
+		kind, name := segmentParam, part[1:len(part)-1]
+		if strings.HasSuffix(name, "...") {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("pattern %q has wildcard before the final segment", pattern)
+			}
+
+			kind, name = segmentWildcard, strings.TrimSuffix(name, "...")
+		}
+
+		if name == "" || strings.ContainsAny(name, "{}.") {
+			return nil, fmt.Errorf("pattern %q has malformed segment %q", pattern, part)
+		}
+
+		if _, ok := names[name]; ok {
+			return nil, fmt.Errorf("pattern %q has duplicate parameter %q", pattern, name)
+		}
+
+		names[name] = struct{}{}
+		segments = append(segments, segment{kind, name})
+	}
+
+	return segments, nil
+}
+
+// find returns the route with the same segments (ignoring the names of
+// parameters), or nil.
+func (t *routeTable) find(segments []segment) *route {
+	for _, rt := range t.routes {
+		if len(rt.segments) != len(segments) {
+			continue
+		}
+
+		same := true
+		for i, s := range rt.segments {
+			if s.kind != segments[i].kind || (s.kind == segmentLiteral && s.value != segments[i].value) {
+				same = false
+				break
+			}
+		}
+
+		if same {
+			return rt
+		}
+	}
+
+	return nil
+}
+
+// sameParamNames returns true if the parameters of both (equally shaped)
+// patterns have the same names.
+func sameParamNames(s1, s2 []segment) bool {
+	for i := range s1 {
+		if s1[i].value != s2[i].value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match returns the most specific route matching the given URL along with
+// the captured parameters, or nil if no route matches.
+func (t *routeTable) match(u *url.URL) (*route, map[string]string) {
+	parts := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/")
+	for i, part := range parts {
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			parts[i] = unescaped
+		}
+	}
+
+	var (
+		best       *route
+		bestParams map[string]string
+	)
+
+	for _, rt := range t.routes {
+		if params, ok := rt.match(parts); ok && (best == nil || rt.moreSpecific(best)) {
+			best, bestParams = rt, params
+		}
+	}
+
+	return best, bestParams
+}
+
+// match returns the parameters captured from the given path segments and
+// a flag indicating whether the path matches the route.
+func (rt *route) match(parts []string) (map[string]string, bool) {
+	params := map[string]string{}
+
+	for i, s := range rt.segments {
+		if i >= len(parts) {
+			return nil, false
+		}
+
+		if s.kind == segmentWildcard {
+			params[s.value] = strings.Join(parts[i:], "/")
+			return params, true
+		}
+
+		switch s.kind {
+		case segmentLiteral:
+			if parts[i] != s.value {
+				return nil, false
+			}
+
+		case segmentParam:
+			if parts[i] == "" {
+				return nil, false
+			}
+
+			params[s.value] = parts[i]
+		}
+	}
+
+	return params, len(parts) == len(rt.segments)
+}
+
+// moreSpecific returns true if the route should be preferred over the
+// other route when both match a path. The first segment which differs in
+// kind decides; otherwise the longer pattern wins.
+func (rt *route) moreSpecific(other *route) bool {
+	for i := 0; i < len(rt.segments) && i < len(other.segments); i++ {
+		if rt.segments[i].kind != other.segments[i].kind {
+			return rt.segments[i].kind < other.segments[i].kind
+		}
+	}
+
+	return len(rt.segments) > len(other.segments)
+}
+
+// handler returns the handler registered for the given method.
+func (rt *route) handler(method string) (HandlerFunc, bool) {
+	handler, ok := rt.handlers[method]
+	if !ok && method == http.MethodHead {
+		handler, ok = rt.handlers[http.MethodGet]
+	}
+
+	return handler, ok
+}
+
+// allowed returns the sorted methods which may be used with the route.
+func (rt *route) allowed() []string {
+	set := map[string]struct{}{http.MethodOptions: {}}
+	for method := range rt.handlers {
+		set[method] = struct{}{}
+	}
+
+	if _, ok := set[http.MethodGet]; ok {
+		set[http.MethodHead] = struct{}{}
+	}
+
+	methods := []string{}
+	for method := range set {
+		methods = append(methods, method)
+	}
+
+	sort.Strings(methods)
+	return methods
+}
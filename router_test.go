@@ -0,0 +1,163 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type RouterSuite struct{}
+
+func (s *RouterSuite) TestParams(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/{id}", func(r *http.Request) Response {
+		return Text("user %s", Param(r, "id"))
+	})
+
+	router.Get("/users/{id}/posts/{post}", func(r *http.Request) Response {
+		return Text("user %s post %s", Param(r, "id"), Param(r, "post"))
+	})
+
+	Expect(serveRouter(router, "GET", "/users/42").Body.String()).To(Equal("user 42"))
+	Expect(serveRouter(router, "GET", "/users/a%2Fb").Body.String()).To(Equal("user a/b"))
+	Expect(serveRouter(router, "GET", "/users/42/posts/7").Body.String()).To(Equal("user 42 post 7"))
+	Expect(serveRouter(router, "GET", "/users/").Code).To(Equal(http.StatusNotFound))
+	Expect(serveRouter(router, "GET", "/users/42/posts").Code).To(Equal(http.StatusNotFound))
+}
+
+func (s *RouterSuite) TestWildcard(t *testing.T) {
+	router := NewRouter()
+	router.Get("/static/{path...}", func(r *http.Request) Response {
+		return Text("path %s", Param(r, "path"))
+	})
+
+	Expect(serveRouter(router, "GET", "/static/css/site.css").Body.String()).To(Equal("path css/site.css"))
+	Expect(serveRouter(router, "GET", "/static/").Body.String()).To(Equal("path "))
+	Expect(serveRouter(router, "GET", "/static").Code).To(Equal(http.StatusNotFound))
+}
+
+func (s *RouterSuite) TestPrecedence(t *testing.T) {
+	router := NewRouter()
+	for _, pattern := range []string{"/{path...}", "/users/{id}", "/users/me", "/users/{id}/{rest...}"} {
+		pattern := pattern
+		router.Get(pattern, func(r *http.Request) Response { return Text("%s", pattern) })
+	}
+
+	Expect(serveRouter(router, "GET", "/users/me").Body.String()).To(Equal("/users/me"))
+	Expect(serveRouter(router, "GET", "/users/42").Body.String()).To(Equal("/users/{id}"))
+	Expect(serveRouter(router, "GET", "/users/42/x/y").Body.String()).To(Equal("/users/{id}/{rest...}"))
+	Expect(serveRouter(router, "GET", "/other").Body.String()).To(Equal("/{path...}"))
+}
+
+func (s *RouterSuite) TestMethodNotAllowed(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/{id}", func(r *http.Request) Response { return Text("get") })
+	router.Delete("/users/{id}", func(r *http.Request) Response { return Text("delete") })
+
+	Expect(serveRouter(router, "DELETE", "/users/1").Body.String()).To(Equal("delete"))
+
+	w := serveRouter(router, "POST", "/users/1")
+	Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+	Expect(w.Header().Get("Allow")).To(Equal("DELETE, GET, HEAD, OPTIONS"))
+}
+
+func (s *RouterSuite) TestHead(t *testing.T) {
+	router := NewRouter()
+	router.Get("/", func(r *http.Request) Response { return Text("content") })
+
+	w := serveRouter(router, "HEAD", "/")
+	Expect(w.Code).To(Equal(http.StatusOK))
+	Expect(w.Header().Get("Content-Length")).To(Equal("7"))
+	Expect(w.Body.Len()).To(Equal(0))
+}
+
+func (s *RouterSuite) TestOptions(t *testing.T) {
+	router := NewRouter()
+	router.Post("/items", func(r *http.Request) Response { return Empty(http.StatusCreated) })
+
+	w := serveRouter(router, "OPTIONS", "/items")
+	Expect(w.Code).To(Equal(http.StatusNoContent))
+	Expect(w.Header().Get("Allow")).To(Equal("OPTIONS, POST"))
+}
+
+func (s *RouterSuite) TestOptionsExplicit(t *testing.T) {
+	router := NewRouter()
+	router.Handle("OPTIONS", "/items", func(r *http.Request) Response { return Text("custom") })
+
+	Expect(serveRouter(router, "OPTIONS", "/items").Body.String()).To(Equal("custom"))
+}
+
+func (s *RouterSuite) TestGroups(t *testing.T) {
+	tag := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(r *http.Request) Response {
+				resp := next(r)
+				resp.AddHeader("X-Middleware", name)
+				return resp
+			}
+		}
+	}
+
+	router := NewRouter()
+	api := router.Group("/api", tag("api"))
+	v1 := api.Group("/v1", tag("v1"))
+
+	router.Get("/", func(r *http.Request) Response { return Text("root") })
+	v1.Get("/users/{id}", func(r *http.Request) Response { return Text("user %s", Param(r, "id")) })
+
+	w := serveRouter(router, "GET", "/api/v1/users/3")
+	Expect(w.Body.String()).To(Equal("user 3"))
+	Expect(w.Header()["X-Middleware"]).To(Equal([]string{"v1", "api"}))
+
+	w = serveRouter(router, "GET", "/")
+	Expect(w.Header()["X-Middleware"]).To(BeEmpty())
+}
+
+func (s *RouterSuite) TestGroupPreflight(t *testing.T) {
+	router := NewRouter()
+	api := router.Group("/api", CORS(WithAllowedOrigins("https://example.com"), WithAllowedMethods("PUT")))
+	api.Put("/items/{id}", func(r *http.Request) Response { return Empty(http.StatusNoContent) })
+
+	req := httptest.NewRequest("OPTIONS", "/api/items/1", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	Expect(w.Code).To(Equal(http.StatusNoContent))
+	Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://example.com"))
+	Expect(w.Header().Get("Access-Control-Allow-Methods")).To(Equal("PUT"))
+}
+
+func (s *RouterSuite) TestNotFound(t *testing.T) {
+	router := NewRouter(WithNotFoundHandler(func(r *http.Request) Response {
+		return Text("missing %s", r.URL.Path).SetStatusCode(http.StatusNotFound)
+	}))
+
+	w := serveRouter(router, "GET", "/nope")
+	Expect(w.Code).To(Equal(http.StatusNotFound))
+	Expect(w.Body.String()).To(Equal("missing /nope"))
+}
+
+func (s *RouterSuite) TestInvalidPatterns(t *testing.T) {
+	for _, pattern := range []string{"users", "/{}", "/{a}/{a}", "/{rest...}/x", "/a{b}", "/{a.b}"} {
+		Expect(func() { NewRouter().Get(pattern, nil) }).To(Panic(), pattern)
+	}
+}
+
+func (s *RouterSuite) TestConflicts(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/{id}", func(r *http.Request) Response { return nil })
+
+	Expect(func() { router.Get("/users/{id}", nil) }).To(Panic())
+	Expect(func() { router.Post("/users/{uid}", nil) }).To(Panic())
+	Expect(func() { router.Post("/users/{id}", nil) }).NotTo(Panic())
+}
+
+func serveRouter(router *Router, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
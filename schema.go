@@ -0,0 +1,304 @@
+package response
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaRegistry reflects over Go types to produce JSON Schemas describing
+// their JSON encoding. Named struct types are registered as components and
+// referenced by name, which also permits recursive types.
+type schemaRegistry struct {
+	schemas map[string]interface{}
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	packagePattern    = regexp.MustCompile(`(?:[\w.-]+/)*[\w-]+\.`)
+	invalidNameChars  = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// newSchemaRegistry creates an empty schema registry.
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: map[string]interface{}{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// schema returns the schema of the given type. An error is returned if the
+// type (or a type it references) cannot be encoded as JSON.
+func (s *schemaRegistry) schema(t reflect.Type) (map[string]interface{}, error) {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+
+	case t == rawMessageType:
+		return map[string]interface{}{}, nil
+
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return map[string]interface{}{}, nil
+
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+
+	case reflect.Ptr:
+		schema, err := s.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return nullable(schema), nil
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(map[string]interface{}{"type": "string", "contentEncoding": "base64"}), nil
+		}
+
+		schema, err := s.arraySchema(t)
+		if err != nil {
+			return nil, err
+		}
+
+		return nullable(schema), nil
+
+	case reflect.Array:
+		return s.arraySchema(t)
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String && !t.Key().Implements(textMarshalerType) {
+			switch t.Key().Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			default:
+				return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+			}
+		}
+
+		values, err := s.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return nullable(map[string]interface{}{"type": "object", "additionalProperties": values}), nil
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+
+		return s.ref(t)
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// arraySchema returns the schema of a slice or array type.
+func (s *schemaRegistry) arraySchema(t reflect.Type) (map[string]interface{}, error) {
+	items, err := s.schema(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"type": "array", "items": items}, nil
+}
+
+// ref registers the schema of the named struct type as a component (if it
+// is not already registered) and returns a reference to it.
+func (s *schemaRegistry) ref(t reflect.Type) (map[string]interface{}, error) {
+	name, ok := s.names[t]
+	if !ok {
+		name = s.uniqueName(t)
+		s.names[t] = name
+		s.schemas[name] = nil
+
+		schema, err := s.structSchema(t)
+		if err != nil {
+			delete(s.names, t)
+			delete(s.schemas, name)
+			return nil, err
+		}
+
+		s.schemas[name] = schema
+	}
+
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}, nil
+}
+
+// uniqueName returns a component name for the type which is not already
+// used by another type. Package paths are removed from the type arguments
+// of generic types.
+func (s *schemaRegistry) uniqueName(t reflect.Type) string {
+	base := strings.Trim(invalidNameChars.ReplaceAllString(packagePattern.ReplaceAllString(t.Name(), ""), "_"), "_")
+
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := s.schemas[name]; !ok {
+			return name
+		}
+
+		name = base + strconv.Itoa(i)
+	}
+}
+
+// structSchema returns the object schema of the given struct type.
+func (s *schemaRegistry) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for _, field := range jsonFields(t) {
+		schema, err := s.schema(field.typ)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %w", field.name, t, err)
+		}
+
+		if field.quoted {
+			schema = map[string]interface{}{"type": "string"}
+		}
+
+		properties[field.name] = schema
+
+		if field.required {
+			required = append(required, field.name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema, nil
+}
+
+// jsonField describes a field in the JSON encoding of a struct.
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+	quoted   bool
+}
+
+// jsonFields returns the fields of the JSON encoding of the struct type.
+// Fields of embedded structs are promoted unless they are shadowed by a
+// field of the outer struct.
+func jsonFields(t reflect.Type) []jsonField {
+	fields := []jsonField{}
+	embedded := []reflect.Type{}
+	seen := map[string]struct{}{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, options, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			if ft := derefType(f.Type); ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		optional := false
+		quoted := false
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "omitempty", "omitzero":
+				optional = true
+			case "string":
+				quoted = isQuotable(f.Type)
+			}
+		}
+
+		seen[name] = struct{}{}
+		fields = append(fields, jsonField{name: name, typ: f.Type, required: !optional, quoted: quoted})
+	}
+
+	for _, et := range embedded {
+		for _, field := range jsonFields(et) {
+			if _, ok := seen[field.name]; !ok {
+				seen[field.name] = struct{}{}
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	return fields
+}
+
+// derefType returns the element type of pointer types.
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+
+	return t
+}
+
+// isQuotable returns true if the string option of a json tag applies to
+// the given field type.
+func isQuotable(t reflect.Type) bool {
+	switch derefType(t).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+
+	return false
+}
+
+// nullable extends the schema to also permit null.
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if t, ok := schema["type"].(string); ok {
+		schema["type"] = []string{t, "null"}
+		return schema
+	}
+
+	if len(schema) == 0 {
+		return schema
+	}
+
+	return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
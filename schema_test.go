@@ -0,0 +1,109 @@
+package response
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type SchemaSuite struct{}
+
+type (
+	schemaBase struct {
+		ID      int       `json:"id"`
+		Created time.Time `json:"created"`
+	}
+
+	schemaUser struct {
+		schemaBase
+		Name     string            `json:"name"`
+		Email    *string           `json:"email"`
+		Tags     []string          `json:"tags,omitempty"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Count    int64             `json:"count,string"`
+		Avatar   []byte            `json:"avatar,omitempty"`
+		Address  net.IP            `json:"address,omitempty"`
+		Extra    json.RawMessage   `json:"extra,omitempty"`
+		Friends  []*schemaUser     `json:"friends,omitempty"`
+		Ignored  string            `json:"-"`
+		Untagged bool
+		private  string
+	}
+
+	schemaPage[T any] struct {
+		Items []T `json:"items"`
+	}
+)
+
+func (s *SchemaSuite) TestPrimitives(t *testing.T) {
+	for value, expected := range map[interface{}]string{
+		true:        `{"type": "boolean"}`,
+		int8(1):     `{"type": "integer"}`,
+		uint(1):     `{"type": "integer", "minimum": 0}`,
+		1.5:         `{"type": "number"}`,
+		"":          `{"type": "string"}`,
+		time.Time{}: `{"type": "string", "format": "date-time"}`,
+		[2]int{}:    `{"type": "array", "items": {"type": "integer"}}`,
+	} {
+		Expect(reflectSchema(newSchemaRegistry(), value)).To(MatchJSON(expected), reflect.TypeOf(value).String())
+	}
+
+	Expect(reflectSchema(newSchemaRegistry(), []string{})).To(MatchJSON(`{"type": ["array", "null"], "items": {"type": "string"}}`))
+	Expect(reflectSchema(newSchemaRegistry(), map[string]float64{})).To(MatchJSON(`{"type": ["object", "null"], "additionalProperties": {"type": "number"}}`))
+	Expect(reflectSchema(newSchemaRegistry(), struct{ A int }{})).To(MatchJSON(`{"type": "object", "properties": {"A": {"type": "integer"}}, "required": ["A"]}`))
+}
+
+func (s *SchemaSuite) TestStruct(t *testing.T) {
+	registry := newSchemaRegistry()
+	Expect(reflectSchema(registry, schemaUser{})).To(MatchJSON(`{"$ref": "#/components/schemas/schemaUser"}`))
+
+	data, err := json.Marshal(registry.schemas)
+	Expect(err).To(BeNil())
+	Expect(data).To(MatchJSON(`{
+		"schemaUser": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"created": {"type": "string", "format": "date-time"},
+				"name": {"type": "string"},
+				"email": {"type": ["string", "null"]},
+				"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+				"labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+				"count": {"type": "string"},
+				"avatar": {"type": ["string", "null"], "contentEncoding": "base64"},
+				"address": {"type": "string"},
+				"extra": {},
+				"friends": {"type": ["array", "null"], "items": {"anyOf": [{"$ref": "#/components/schemas/schemaUser"}, {"type": "null"}]}},
+				"Untagged": {"type": "boolean"}
+			},
+			"required": ["name", "email", "count", "Untagged", "id", "created"]
+		}
+	}`))
+}
+
+func (s *SchemaSuite) TestGenericNames(t *testing.T) {
+	registry := newSchemaRegistry()
+	Expect(reflectSchema(registry, schemaPage[schemaBase]{})).To(MatchJSON(`{"$ref": "#/components/schemas/schemaPage_schemaBase"}`))
+	Expect(registry.schemas).To(HaveKey("schemaBase"))
+}
+
+func (s *SchemaSuite) TestUnsupported(t *testing.T) {
+	_, err := newSchemaRegistry().schema(reflect.TypeOf(struct{ C chan int }{}))
+	Expect(err).To(MatchError("field C of struct { C chan int }: unsupported type chan int"))
+
+	_, err = newSchemaRegistry().schema(reflect.TypeOf(map[bool]string{}))
+	Expect(err).To(MatchError("unsupported map key type bool"))
+}
+
+func reflectSchema(registry *schemaRegistry, value interface{}) []byte {
+	schema, err := registry.schema(reflect.TypeOf(value))
+	Expect(err).To(BeNil())
+
+	data, err := json.Marshal(schema)
+	Expect(err).To(BeNil())
+	return data
+}
@@ -0,0 +1,186 @@
+package response
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	securityConfig struct {
+		headers           map[string]string
+		permissionsPolicy []string
+		csp               *ContentSecurityPolicy
+		cspReportOnly     bool
+	}
+
+	// SecurityConfigFunc is a function used to configure the SecurityHeaders
+	// middleware.
+	SecurityConfigFunc func(*securityConfig)
+
+	nonceKeyType struct{}
+)
+
+var nonceKey = nonceKeyType{}
+
+// WithHSTS sets the Strict-Transport-Security header. This header should
+// only be sent by servers reachable exclusively over HTTPS.
+func WithHSTS(maxAge time.Duration, includeSubdomains, preload bool) SecurityConfigFunc {
+	return func(c *securityConfig) {
+		value := fmt.Sprintf("max-age=%d", int64(maxAge/time.Second))
+		if includeSubdomains {
+			value += "; includeSubDomains"
+		}
+
+		if preload {
+			value += "; preload"
+		}
+
+		c.headers["Strict-Transport-Security"] = value
+	}
+}
+
+// WithReferrerPolicy sets the Referrer-Policy header. The default policy
+// is strict-origin-when-cross-origin. An empty value omits the header.
+func WithReferrerPolicy(policy string) SecurityConfigFunc {
+	return func(c *securityConfig) { c.headers["Referrer-Policy"] = policy }
+}
+
+// WithoutContentTypeNosniff omits the X-Content-Type-Options header, which
+// is set to nosniff by default.
+func WithoutContentTypeNosniff() SecurityConfigFunc {
+	return func(c *securityConfig) { c.headers["X-Content-Type-Options"] = "" }
+}
+
+// WithFrameOptions sets the X-Frame-Options header (DENY or SAMEORIGIN).
+func WithFrameOptions(value string) SecurityConfigFunc {
+	return func(c *securityConfig) { c.headers["X-Frame-Options"] = value }
+}
+
+// WithPermission adds a feature to the Permissions-Policy header. Each entry
+// of the allowlist is either self, *, or an origin. An empty allowlist
+// disables the feature entirely.
+func WithPermission(feature string, allowlist ...string) SecurityConfigFunc {
+	return func(c *securityConfig) {
+		origins := []string{}
+		for _, origin := range allowlist {
+			if origin != "self" && origin != "*" {
+				origin = formatString(origin)
+			}
+
+			origins = append(origins, origin)
+		}
+
+		c.permissionsPolicy = append(c.permissionsPolicy, feature+"=("+strings.Join(origins, " ")+")")
+	}
+}
+
+// WithCrossOriginOpenerPolicy sets the Cross-Origin-Opener-Policy header
+// (such as same-origin).
+func WithCrossOriginOpenerPolicy(policy string) SecurityConfigFunc {
+	return func(c *securityConfig) { c.headers["Cross-Origin-Opener-Policy"] = policy }
+}
+
+// WithCrossOriginEmbedderPolicy sets the Cross-Origin-Embedder-Policy
+// header (such as require-corp).
+func WithCrossOriginEmbedderPolicy(policy string) SecurityConfigFunc {
+	return func(c *securityConfig) { c.headers["Cross-Origin-Embedder-Policy"] = policy }
+}
+
+// WithCrossOriginResourcePolicy sets the Cross-Origin-Resource-Policy
+// header (such as same-site).
+func WithCrossOriginResourcePolicy(policy string) SecurityConfigFunc {
+	return func(c *securityConfig) { c.headers["Cross-Origin-Resource-Policy"] = policy }
+}
+
+// WithContentSecurityPolicy sets the Content-Security-Policy header. If the
+// policy contains SourceNonce, a new nonce is generated for each request.
+func WithContentSecurityPolicy(policy *ContentSecurityPolicy) SecurityConfigFunc {
+	return func(c *securityConfig) { c.csp = policy }
+}
+
+// WithCSPReportOnly sends the content security policy in the header
+// Content-Security-Policy-Report-Only so that violations are reported
+// but not enforced.
+func WithCSPReportOnly() SecurityConfigFunc {
+	return func(c *securityConfig) { c.cspReportOnly = true }
+}
+
+// SecurityHeaders creates a middleware which adds security-related headers
+// to every response. Headers already set by the wrapped handler are left
+// untouched.
+func SecurityHeaders(configs ...SecurityConfigFunc) Middleware {
+	config := &securityConfig{
+		headers: map[string]string{
+			"X-Content-Type-Options": "nosniff",
+			"Referrer-Policy":        "strict-origin-when-cross-origin",
+		},
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	if len(config.permissionsPolicy) > 0 {
+		config.headers["Permissions-Policy"] = strings.Join(config.permissionsPolicy, ", ")
+	}
+
+	cspHeader := "Content-Security-Policy"
+	if config.cspReportOnly {
+		cspHeader += "-Report-Only"
+	}
+
+	return func(f HandlerFunc) HandlerFunc {
+		return func(r *http.Request) Response {
+			nonce := ""
+			if config.csp != nil && config.csp.UsesNonce() {
+				nonce = generateNonce()
+				r = r.WithContext(context.WithValue(r.Context(), nonceKey, nonce))
+			}
+
+			resp := f(r)
+
+			for name, value := range config.headers {
+				setDefaultHeader(resp, name, value)
+			}
+
+			if config.csp != nil {
+				setDefaultHeader(resp, cspHeader, config.csp.Build(nonce))
+			}
+
+			return resp
+		}
+	}
+}
+
+// CSPNonce returns the content security policy nonce generated for the
+// current request by the SecurityHeaders middleware. Handlers use this value
+// as the nonce attribute of inline script and style elements.
+func CSPNonce(ctx context.Context) string {
+	if nonce, ok := ctx.Value(nonceKey).(string); ok {
+		return nonce
+	}
+
+	return ""
+}
+
+// setDefaultHeader sets the header on the response if it is not already set.
+func setDefaultHeader(resp Response, name, value string) {
+	if resp.Header(name) == "" {
+		resp.SetHeader(name, value)
+	}
+}
+
+// generateNonce creates a random base64-encoded value.
+func generateNonce() string {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		panic(fmt.Sprintf("failed to generate nonce (%s)", err))
+	}
+
+	return base64.StdEncoding.EncodeToString(buffer)
+}
@@ -0,0 +1,88 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type SecuritySuite struct{}
+
+func (s *SecuritySuite) TestDefaults(t *testing.T) {
+	handler := SecurityHeaders()(func(r *http.Request) Response {
+		return Empty(http.StatusNoContent)
+	})
+
+	resp := handler(httptest.NewRequest("GET", "/", nil))
+	Expect(resp.Header("X-Content-Type-Options")).To(Equal("nosniff"))
+	Expect(resp.Header("Referrer-Policy")).To(Equal("strict-origin-when-cross-origin"))
+	Expect(resp.Header("Strict-Transport-Security")).To(BeEmpty())
+	Expect(resp.Header("Content-Security-Policy")).To(BeEmpty())
+}
+
+func (s *SecuritySuite) TestConfigured(t *testing.T) {
+	middleware := SecurityHeaders(
+		WithHSTS(365*24*time.Hour, true, true),
+		WithReferrerPolicy("no-referrer"),
+		WithoutContentTypeNosniff(),
+		WithFrameOptions("DENY"),
+		WithPermission("camera"),
+		WithPermission("geolocation", "self", "https://maps.example.com"),
+		WithCrossOriginOpenerPolicy("same-origin"),
+		WithCrossOriginEmbedderPolicy("require-corp"),
+		WithCrossOriginResourcePolicy("same-site"),
+		WithContentSecurityPolicy(NewContentSecurityPolicy().Add(DirectiveDefaultSrc, SourceSelf)),
+	)
+
+	resp := middleware(func(r *http.Request) Response {
+		return Empty(http.StatusNoContent)
+	})(httptest.NewRequest("GET", "/", nil))
+
+	Expect(resp.Header("Strict-Transport-Security")).To(Equal("max-age=31536000; includeSubDomains; preload"))
+	Expect(resp.Header("Referrer-Policy")).To(Equal("no-referrer"))
+	Expect(resp.Header("X-Content-Type-Options")).To(BeEmpty())
+	Expect(resp.Header("X-Frame-Options")).To(Equal("DENY"))
+	Expect(resp.Header("Permissions-Policy")).To(Equal(`camera=(), geolocation=(self "https://maps.example.com")`))
+	Expect(resp.Header("Cross-Origin-Opener-Policy")).To(Equal("same-origin"))
+	Expect(resp.Header("Cross-Origin-Embedder-Policy")).To(Equal("require-corp"))
+	Expect(resp.Header("Cross-Origin-Resource-Policy")).To(Equal("same-site"))
+	Expect(resp.Header("Content-Security-Policy")).To(Equal("default-src 'self'"))
+}
+
+func (s *SecuritySuite) TestHandlerHeadersTakePrecedence(t *testing.T) {
+	handler := SecurityHeaders(WithFrameOptions("DENY"))(func(r *http.Request) Response {
+		return Empty(http.StatusNoContent).SetHeader("X-Frame-Options", "SAMEORIGIN")
+	})
+
+	resp := handler(httptest.NewRequest("GET", "/", nil))
+	Expect(resp.Header("X-Frame-Options")).To(Equal("SAMEORIGIN"))
+}
+
+func (s *SecuritySuite) TestNonce(t *testing.T) {
+	var (
+		nonces = []string{}
+		policy = NewContentSecurityPolicy().Add(DirectiveScriptSrc, SourceSelf, SourceNonce)
+	)
+
+	handler := SecurityHeaders(WithContentSecurityPolicy(policy), WithCSPReportOnly())(func(r *http.Request) Response {
+		nonces = append(nonces, CSPNonce(r.Context()))
+		return Empty(http.StatusNoContent)
+	})
+
+	resp1 := handler(httptest.NewRequest("GET", "/", nil))
+	resp2 := handler(httptest.NewRequest("GET", "/", nil))
+
+	Expect(nonces).To(HaveLen(2))
+	Expect(nonces[0]).NotTo(BeEmpty())
+	Expect(nonces[0]).NotTo(Equal(nonces[1]))
+	Expect(resp1.Header("Content-Security-Policy")).To(BeEmpty())
+	Expect(resp1.Header("Content-Security-Policy-Report-Only")).To(Equal("script-src 'self' 'nonce-" + nonces[0] + "'"))
+	Expect(resp2.Header("Content-Security-Policy-Report-Only")).To(Equal("script-src 'self' 'nonce-" + nonces[1] + "'"))
+}
+
+func (s *SecuritySuite) TestNonceMissing(t *testing.T) {
+	Expect(CSPNonce(httptest.NewRequest("GET", "/", nil).Context())).To(BeEmpty())
+}
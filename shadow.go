@@ -0,0 +1,241 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	// ShadowSink receives the report of a shadowed request whose primary
+	// and shadow responses differ.
+	ShadowSink func(r *http.Request, report *DiffReport)
+
+	// ShadowNormalizer transforms the headers and body of both responses
+	// before they are compared (e.g. to remove volatile fields).
+	ShadowNormalizer func(header http.Header, body []byte) (http.Header, []byte)
+
+	shadowConfig struct {
+		diffConfigs []DiffConfigFunc
+		normalizers []ShadowNormalizer
+		maxBodySize int64
+		timeout     time.Duration
+	}
+
+	// ShadowConfigFunc is a function used to configure the Shadow middleware.
+	ShadowConfigFunc func(*shadowConfig)
+
+	// captureBuffer retains a bounded prefix of the data written to it. Writes
+	// never fail so that capturing never affects the client.
+	captureBuffer struct {
+		bytes.Buffer
+		limit    int64
+		overflow bool
+	}
+
+	// teeWriter copies the data written to the client into a capture buffer
+	// and forwards flushes.
+	teeWriter struct {
+		io.Writer
+		capture *captureBuffer
+	}
+)
+
+// WithShadowDiffConfig passes the given config functions to Diff when the
+// responses are compared.
+func WithShadowDiffConfig(configs ...DiffConfigFunc) ShadowConfigFunc {
+	return func(c *shadowConfig) { c.diffConfigs = append(c.diffConfigs, configs...) }
+}
+
+// WithShadowNormalizer registers a function applied to both responses
+// before they are compared. Normalizers are applied in order.
+func WithShadowNormalizer(f ShadowNormalizer) ShadowConfigFunc {
+	return func(c *shadowConfig) { c.normalizers = append(c.normalizers, f) }
+}
+
+// WithShadowMaxBodySize sets the maximum size of request and response bodies
+// which are buffered for comparison. Requests or responses with a larger body
+// are not compared. The default limit is 1MiB.
+func WithShadowMaxBodySize(maxBodySize int64) ShadowConfigFunc {
+	return func(c *shadowConfig) { c.maxBodySize = maxBodySize }
+}
+
+// WithShadowTimeout sets the duration after which the shadow handler's
+// request context is canceled and the comparison is abandoned if the primary
+// response has not been written. The default timeout is 30 seconds.
+func WithShadowTimeout(timeout time.Duration) ShadowConfigFunc {
+	return func(c *shadowConfig) { c.timeout = timeout }
+}
+
+// Shadow creates middleware which invokes the shadow handler alongside the
+// wrapped (primary) handler. The response of the primary handler is sent to
+// the client; the shadow handler is invoked concurrently with a clone of the
+// request whose context is not canceled with the client request. Once the
+// primary response has been written, both responses are compared with Diff
+// and the sink is invoked if they differ. The body of the primary response
+// is captured as it is written, so client latency is unaffected. Trailers
+// are not compared, HEAD requests are not shadowed, and requests whose
+// primary response fails to be written are not compared. An error writing
+// the shadow response (or a panic in the shadow handler) is reported as a
+// mismatch.
+func Shadow(shadow HandlerFunc, sink ShadowSink, configs ...ShadowConfigFunc) Middleware {
+	config := &shadowConfig{
+		maxBodySize: 1 << 20,
+		timeout:     30 * time.Second,
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(r *http.Request) Response {
+			if r.Method == http.MethodHead {
+				return next(r)
+			}
+
+			body, ok := bufferRequestBody(r, config.maxBodySize)
+			if !ok {
+				return next(r)
+			}
+
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), config.timeout)
+			shadowRequest := r.Clone(ctx)
+			if body != nil {
+				shadowRequest.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			resp := next(r)
+			primary, ok := resp.(*response)
+			if !ok {
+				cancel()
+				return resp
+			}
+
+			var (
+				capture    = &captureBuffer{limit: config.maxBodySize}
+				done       = make(chan struct{})
+				primaryErr error
+			)
+
+			primary.DecorateWriter(func(w io.Writer) io.Writer {
+				return &teeWriter{w, capture}
+			})
+
+			primary.AddCallback(func(err error) {
+				primaryErr = err
+				close(done)
+			})
+
+			go func() {
+				defer cancel()
+
+				statusCode, header, shadowBody, shadowErr := serializeShadow(shadow, shadowRequest)
+
+				select {
+				case <-done:
+				case <-ctx.Done():
+					return
+				}
+
+				if primaryErr != nil || capture.overflow || int64(len(shadowBody)) > config.maxBodySize {
+					return
+				}
+
+				primaryHeader := primary.header.Clone()
+				if len(primary.trailerNames()) > 0 {
+					// Omitted when written (see writeHeader)
+					primaryHeader.Del("Content-Length")
+				}
+
+				a := config.reconstruct(primary.statusCode, primaryHeader, capture.Bytes())
+				b := config.reconstruct(statusCode, header, shadowBody)
+
+				report := Diff(a, b, config.diffConfigs...)
+				report.ErrB = shadowErr
+
+				if !report.Equal() {
+					sink(shadowRequest, report)
+				}
+			}()
+
+			return resp
+		}
+	}
+}
+
+// bufferRequestBody reads the body of the request so that it can be read
+// by both handlers. The body of the request is replaced with an equivalent
+// reader. The returned flag is false if the body exceeds the given limit.
+func bufferRequestBody(r *http.Request, limit int64) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	r.Body = &readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	return body, err == nil && int64(len(body)) <= limit
+}
+
+// serializeShadow invokes the shadow handler and serializes its response.
+// Trailers are discarded.
+func serializeShadow(shadow HandlerFunc, r *http.Request) (statusCode int, header http.Header, body []byte, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			statusCode, header, body, err = http.StatusInternalServerError, http.Header{}, nil, fmt.Errorf("shadow handler panicked: %v", recovered)
+		}
+	}()
+
+	resp := shadow(r)
+	statusCode = resp.StatusCode()
+	header, body, err = Serialize(resp)
+
+	for name := range header {
+		if name == "Trailer" || strings.HasPrefix(name, http.TrailerPrefix) {
+			delete(header, name)
+		}
+	}
+
+	return statusCode, header, body, err
+}
+
+// reconstruct applies the normalizers and creates a response for comparison.
+func (c *shadowConfig) reconstruct(statusCode int, header http.Header, body []byte) Response {
+	for _, f := range c.normalizers {
+		header, body = f(header, body)
+	}
+
+	return Reconstruct(statusCode, header, body)
+}
+
+// Write retains the data up to the limit of the buffer. Data beyond the
+// limit is discarded silently, but the entire write is reported as written.
+func (b *captureBuffer) Write(p []byte) (int, error) {
+	data := p
+	if remaining := b.limit - int64(b.Len()); int64(len(data)) > remaining {
+		b.overflow = true
+		data = data[:remaining]
+	}
+
+	b.Buffer.Write(data)
+	return len(p), nil
+}
+
+// Write writes the data to the underlying writer and captures the portion
+// which was written successfully.
+func (w *teeWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.capture.Write(p[:n])
+	return n, err
+}
+
+// Flush flushes the underlying writer if it is a flusher.
+func (w *teeWriter) Flush() {
+	if f, ok := w.Writer.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,189 @@
+package response
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type ShadowSuite struct{}
+
+type shadowReport struct {
+	request *http.Request
+	report  *DiffReport
+}
+
+func (s *ShadowSuite) TestEqual(t *testing.T) {
+	reports := make(chan shadowReport, 1)
+	shadowed := make(chan struct{})
+
+	primary := func(r *http.Request) Response {
+		return JSON(map[string]int{"a": 1, "b": 2})
+	}
+
+	shadow := func(r *http.Request) Response {
+		defer close(shadowed)
+		return Respond([]byte(`{"b":2,"a":1}`)).SetHeader("Content-Type", "application/json")
+	}
+
+	handler := Shadow(shadow, func(r *http.Request, report *DiffReport) {
+		reports <- shadowReport{r, report}
+	})(primary)
+
+	w := httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	Expect(w.Body.String()).To(MatchJSON(`{"a": 1, "b": 2}`))
+
+	Eventually(shadowed).Should(BeClosed())
+	Consistently(reports, 50*time.Millisecond).ShouldNot(Receive())
+}
+
+func (s *ShadowSuite) TestMismatch(t *testing.T) {
+	reports := make(chan shadowReport, 1)
+
+	primary := func(r *http.Request) Response {
+		body, _ := io.ReadAll(r.Body)
+		return Respond(body).SetHeader("X-Version", "1")
+	}
+
+	shadow := func(r *http.Request) Response {
+		body, _ := io.ReadAll(r.Body)
+		return Respond(bytes.ToUpper(body)).SetHeader("X-Version", "2")
+	}
+
+	handler := Shadow(shadow, func(r *http.Request, report *DiffReport) {
+		reports <- shadowReport{r, report}
+	})(primary)
+
+	w := httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, httptest.NewRequest("POST", "/echo", bytes.NewReader([]byte("hello"))))
+	Expect(w.Body.String()).To(Equal("hello"))
+
+	var result shadowReport
+	Eventually(reports).Should(Receive(&result))
+	Expect(result.request.URL.Path).To(Equal("/echo"))
+	Expect(result.report.Headers).To(ConsistOf(Difference{DiffChanged, "X-Version", "1", "2"}))
+	Expect(result.report.Body).To(HaveLen(1))
+	Expect(result.report.Body[0].A).To(Equal("hello"))
+	Expect(result.report.Body[0].B).To(Equal("HELLO"))
+}
+
+func (s *ShadowSuite) TestLatency(t *testing.T) {
+	reports := make(chan shadowReport, 1)
+	release := make(chan struct{})
+
+	shadow := func(r *http.Request) Response {
+		<-release
+		return Empty(http.StatusTeapot)
+	}
+
+	handler := Shadow(shadow, func(r *http.Request, report *DiffReport) {
+		reports <- shadowReport{r, report}
+	})(func(r *http.Request) Response {
+		return Empty(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	Expect(w.Code).To(Equal(http.StatusOK))
+	close(release)
+
+	var result shadowReport
+	Eventually(reports).Should(Receive(&result))
+	Expect(result.report.StatusCode).To(Equal(&Difference{DiffChanged, "status", "200", "418"}))
+}
+
+func (s *ShadowSuite) TestNormalizer(t *testing.T) {
+	reports := make(chan shadowReport, 1)
+	shadowed := make(chan struct{})
+
+	handler := Shadow(
+		func(r *http.Request) Response {
+			defer close(shadowed)
+			return JSON(map[string]string{"id": "b"}).SetHeader("X-Request-Id", "2")
+		},
+		func(r *http.Request, report *DiffReport) { reports <- shadowReport{r, report} },
+		WithShadowDiffConfig(WithDiffIgnoredHeaders("X-Request-Id")),
+		WithShadowNormalizer(func(header http.Header, body []byte) (http.Header, []byte) {
+			return header, bytes.NewBufferString(`{"id": "*"}`).Bytes()
+		}),
+	)(func(r *http.Request) Response {
+		return JSON(map[string]string{"id": "a"}).SetHeader("X-Request-Id", "1")
+	})
+
+	Convert(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	Eventually(shadowed).Should(BeClosed())
+	Consistently(reports, 50*time.Millisecond).ShouldNot(Receive())
+}
+
+func (s *ShadowSuite) TestPanic(t *testing.T) {
+	reports := make(chan shadowReport, 1)
+
+	handler := Shadow(func(r *http.Request) Response {
+		panic("oops")
+	}, func(r *http.Request, report *DiffReport) {
+		reports <- shadowReport{r, report}
+	})(func(r *http.Request) Response {
+		return Empty(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	Expect(w.Code).To(Equal(http.StatusOK))
+
+	var result shadowReport
+	Eventually(reports).Should(Receive(&result))
+	Expect(result.report.ErrB).To(MatchError("shadow handler panicked: oops"))
+}
+
+func (s *ShadowSuite) TestLargeRequestBody(t *testing.T) {
+	called := make(chan struct{}, 1)
+
+	handler := Shadow(func(r *http.Request) Response {
+		called <- struct{}{}
+		return Empty(http.StatusOK)
+	}, func(r *http.Request, report *DiffReport) {}, WithShadowMaxBodySize(4))(func(r *http.Request) Response {
+		body, _ := io.ReadAll(r.Body)
+		return Respond(body)
+	})
+
+	w := httptest.NewRecorder()
+	Convert(handler).ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewReader([]byte("too large"))))
+	Expect(w.Body.String()).To(Equal("too large"))
+	Consistently(called, 50*time.Millisecond).ShouldNot(Receive())
+}
+
+func (s *ShadowSuite) TestHead(t *testing.T) {
+	called := make(chan struct{}, 1)
+
+	handler := Shadow(func(r *http.Request) Response {
+		called <- struct{}{}
+		return Empty(http.StatusOK)
+	}, func(r *http.Request, report *DiffReport) {})(func(r *http.Request) Response {
+		return Respond([]byte("body"))
+	})
+
+	Convert(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("HEAD", "/", nil))
+	Consistently(called, 50*time.Millisecond).ShouldNot(Receive())
+}
+
+func (s *ShadowSuite) TestCaptureBufferLimit(t *testing.T) {
+	capture := &captureBuffer{limit: 4}
+
+	n, err := capture.Write([]byte("abc"))
+	Expect(err).To(BeNil())
+	Expect(n).To(Equal(3))
+	Expect(capture.overflow).To(BeFalse())
+
+	n, err = capture.Write([]byte("defg"))
+	Expect(err).To(BeNil())
+	Expect(n).To(Equal(4))
+	Expect(capture.overflow).To(BeTrue())
+	Expect(capture.String()).To(Equal("abcd"))
+}
@@ -0,0 +1,394 @@
+package response
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// SigningKey produces signatures over a signature base (see RFC 9421).
+	SigningKey interface {
+		// KeyID returns the value of the keyid signature parameter.
+		KeyID() string
+
+		// Algorithm returns the value of the alg signature parameter.
+		Algorithm() string
+
+		// Sign returns the signature of the given signature base.
+		Sign(data []byte) ([]byte, error)
+	}
+
+	// VerifyingKey checks signatures over a signature base.
+	VerifyingKey interface {
+		// KeyID returns the value of the keyid signature parameter.
+		KeyID() string
+
+		// Algorithm returns the value of the alg signature parameter.
+		Algorithm() string
+
+		// Verify returns an error if the signature does not match the
+		// given signature base.
+		Verify(data, signature []byte) error
+	}
+
+	// HMACKey is a shared secret used to sign and verify signatures with
+	// the hmac-sha256 algorithm.
+	HMACKey struct {
+		keyID  string
+		secret []byte
+	}
+
+	// Ed25519SigningKey is a private key used to sign with the ed25519
+	// algorithm.
+	Ed25519SigningKey struct {
+		keyID string
+		key   ed25519.PrivateKey
+	}
+
+	// Ed25519VerifyingKey is a public key used to verify signatures made
+	// with the ed25519 algorithm.
+	Ed25519VerifyingKey struct {
+		keyID string
+		key   ed25519.PublicKey
+	}
+
+	signatureConfig struct {
+		label      string
+		components []string
+		now        time.Time
+		expires    time.Duration
+	}
+
+	// SignatureConfigFunc is a function used to configure the Sign and
+	// VerifySignature functions.
+	SignatureConfigFunc func(*signatureConfig)
+)
+
+// ErrInvalidSignature occurs when a signature does not match its content.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// ensure we conform to interfaces
+var (
+	_ SigningKey   = &HMACKey{}
+	_ VerifyingKey = &HMACKey{}
+	_ SigningKey   = &Ed25519SigningKey{}
+	_ VerifyingKey = &Ed25519VerifyingKey{}
+)
+
+// NewHMACKey creates a key which signs and verifies with the given secret.
+func NewHMACKey(keyID string, secret []byte) *HMACKey {
+	return &HMACKey{keyID: keyID, secret: secret}
+}
+
+// KeyID returns the value of the keyid signature parameter.
+func (k *HMACKey) KeyID() string {
+	return k.keyID
+}
+
+// Algorithm returns the value of the alg signature parameter.
+func (k *HMACKey) Algorithm() string {
+	return "hmac-sha256"
+}
+
+// Sign returns the signature of the given signature base.
+func (k *HMACKey) Sign(data []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// Verify returns an error if the signature does not match the given
+// signature base.
+func (k *HMACKey) Verify(data, signature []byte) error {
+	expected, _ := k.Sign(data)
+	if !hmac.Equal(expected, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// NewEd25519SigningKey creates a key which signs with the given private key.
+func NewEd25519SigningKey(keyID string, key ed25519.PrivateKey) *Ed25519SigningKey {
+	return &Ed25519SigningKey{keyID: keyID, key: key}
+}
+
+// KeyID returns the value of the keyid signature parameter.
+func (k *Ed25519SigningKey) KeyID() string {
+	return k.keyID
+}
+
+// Algorithm returns the value of the alg signature parameter.
+func (k *Ed25519SigningKey) Algorithm() string {
+	return "ed25519"
+}
+
+// Sign returns the signature of the given signature base.
+func (k *Ed25519SigningKey) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(k.key, data), nil
+}
+
+// NewEd25519VerifyingKey creates a key which verifies with the given
+// public key.
+func NewEd25519VerifyingKey(keyID string, key ed25519.PublicKey) *Ed25519VerifyingKey {
+	return &Ed25519VerifyingKey{keyID: keyID, key: key}
+}
+
+// KeyID returns the value of the keyid signature parameter.
+func (k *Ed25519VerifyingKey) KeyID() string {
+	return k.keyID
+}
+
+// Algorithm returns the value of the alg signature parameter.
+func (k *Ed25519VerifyingKey) Algorithm() string {
+	return "ed25519"
+}
+
+// Verify returns an error if the signature does not match the given
+// signature base.
+func (k *Ed25519VerifyingKey) Verify(data, signature []byte) error {
+	if !ed25519.Verify(k.key, data, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// WithSignatureLabel sets the label which identifies the signature within
+// the Signature-Input and Signature fields. The default label is sig1. When
+// verifying without a label, the first signature is checked.
+func WithSignatureLabel(label string) SignatureConfigFunc {
+	return func(c *signatureConfig) { c.label = label }
+}
+
+// WithCoveredComponents sets the components covered by the signature. Each
+// component is either the derived component @status or the lowercase name
+// of a header (such as content-type or content-digest). By default, the
+// status code and the content-type and content-digest headers (if present)
+// are covered.
+func WithCoveredComponents(components ...string) SignatureConfigFunc {
+	return func(c *signatureConfig) { c.components = components }
+}
+
+// WithSignatureTime sets the time used as the created parameter of a new
+// signature, or as the current time when checking expiration during
+// verification. The default is the current time.
+func WithSignatureTime(now time.Time) SignatureConfigFunc {
+	return func(c *signatureConfig) { c.now = now }
+}
+
+// WithSignatureExpiration adds an expires parameter to the signature set
+// to the given duration after its creation.
+func WithSignatureExpiration(expires time.Duration) SignatureConfigFunc {
+	return func(c *signatureConfig) { c.expires = expires }
+}
+
+// Sign adds Signature-Input and Signature headers to the response which
+// cover the configured components. Signing must occur after all covered
+// headers have been set. An error is returned if a covered header is not
+// present (for example, a content-digest sent as a trailer).
+func Sign(resp Response, key SigningKey, configs ...SignatureConfigFunc) error {
+	config := newSignatureConfig(configs)
+	if config.label == "" {
+		config.label = "sig1"
+	}
+
+	components := config.components
+	if components == nil {
+		components = []string{"@status"}
+
+		for _, name := range []string{"content-type", "content-digest"} {
+			if resp.Header(name) != "" {
+				components = append(components, name)
+			}
+		}
+	}
+
+	identifiers := []string{}
+	for _, component := range components {
+		identifiers = append(identifiers, formatString(strings.ToLower(component)))
+	}
+
+	params := fmt.Sprintf("(%s);created=%d", strings.Join(identifiers, " "), config.now.Unix())
+	if config.expires > 0 {
+		params += fmt.Sprintf(";expires=%d", config.now.Add(config.expires).Unix())
+	}
+
+	params += ";keyid=" + formatString(key.KeyID()) + ";alg=" + formatString(key.Algorithm())
+
+	base, err := signatureBase(resp.StatusCode(), responseHeaderValues(resp), components, params)
+	if err != nil {
+		return err
+	}
+
+	signature, err := key.Sign(base)
+	if err != nil {
+		return err
+	}
+
+	resp.AddHeader("Signature-Input", config.label+"="+params)
+	resp.AddHeader("Signature", config.label+"="+formatByteSequence(signature))
+	return nil
+}
+
+// VerifySignature checks a signature of a serialized response (such as the
+// values returned from Serialize) against the given key. An error is returned
+// if the signature is missing, malformed, expired, created for a different
+// key, or does not match the covered components.
+func VerifySignature(statusCode int, header http.Header, key VerifyingKey, configs ...SignatureConfigFunc) error {
+	config := newSignatureConfig(configs)
+
+	params, ok := findMember(header, "Signature-Input", config.label)
+	if !ok {
+		return fmt.Errorf("missing signature input")
+	}
+
+	value, ok := findMember(header, "Signature", config.label)
+	if !ok {
+		return fmt.Errorf("missing signature")
+	}
+
+	signature, ok := parseByteSequence(value)
+	if !ok {
+		return fmt.Errorf("malformed signature")
+	}
+
+	end := strings.Index(params, ")")
+	if !strings.HasPrefix(params, "(") || end < 0 {
+		return fmt.Errorf("malformed signature input")
+	}
+
+	components := []string{}
+	for _, identifier := range strings.Fields(params[1:end]) {
+		component, ok := parseString(identifier)
+		if !ok {
+			return fmt.Errorf("malformed component identifier %s", identifier)
+		}
+
+		components = append(components, component)
+	}
+
+	for _, param := range splitTopLevel(params[end+1:], ';')[1:] {
+		parts := strings.SplitN(param, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		switch parts[0] {
+		case "keyid":
+			if keyID, _ := parseString(parts[1]); keyID != key.KeyID() {
+				return fmt.Errorf("signature created by key %s", parts[1])
+			}
+
+		case "alg":
+			if algorithm, _ := parseString(parts[1]); algorithm != key.Algorithm() {
+				return fmt.Errorf("signature created with algorithm %s", parts[1])
+			}
+
+		case "expires":
+			expires, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("malformed expires parameter")
+			}
+
+			if !config.now.Before(time.Unix(expires, 0)) {
+				return fmt.Errorf("signature expired")
+			}
+		}
+	}
+
+	base, err := signatureBase(statusCode, headerValues(header), components, params)
+	if err != nil {
+		return err
+	}
+
+	return key.Verify(base, signature)
+}
+
+// newSignatureConfig applies the given config functions over defaults.
+func newSignatureConfig(configs []SignatureConfigFunc) *signatureConfig {
+	config := &signatureConfig{now: time.Now()}
+	for _, f := range configs {
+		f(config)
+	}
+
+	return config
+}
+
+// signatureBase constructs the canonical string which is signed for the
+// given covered components and serialized signature parameters.
+func signatureBase(statusCode int, values func(string) []string, components []string, params string) ([]byte, error) {
+	lines := []string{}
+	for _, component := range components {
+		component = strings.ToLower(component)
+
+		var value string
+		switch {
+		case component == "@status":
+			value = strconv.Itoa(statusCode)
+
+		case strings.HasPrefix(component, "@"):
+			return nil, fmt.Errorf("unsupported derived component %s", component)
+
+		default:
+			vs := values(http.CanonicalHeaderKey(component))
+			if len(vs) == 0 {
+				return nil, fmt.Errorf("covered component %s is not present", component)
+			}
+
+			trimmed := []string{}
+			for _, v := range vs {
+				trimmed = append(trimmed, strings.TrimSpace(v))
+			}
+
+			value = strings.Join(trimmed, ", ")
+		}
+
+		lines = append(lines, formatString(component)+": "+value)
+	}
+
+	lines = append(lines, `"@signature-params": `+params)
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// findMember returns the raw value of the dictionary member with the given
+// key within the given header. If the key is empty, the first member is
+// returned.
+func findMember(header http.Header, name, key string) (string, bool) {
+	for _, member := range parseDictionary(strings.Join(header[name], ", ")) {
+		if key == "" || member.key == key {
+			return member.value, true
+		}
+	}
+
+	return "", false
+}
+
+// headerValues returns a function which looks up all values of a header.
+func headerValues(header http.Header) func(string) []string {
+	return func(name string) []string { return header[name] }
+}
+
+// responseHeaderValues returns a function which looks up all values of a
+// header set on the response. Responses not created by this package only
+// expose the first value of each header.
+func responseHeaderValues(resp Response) func(string) []string {
+	if r, ok := resp.(*response); ok {
+		return headerValues(r.header)
+	}
+
+	return func(name string) []string {
+		if val := resp.Header(name); val != "" {
+			return []string{val}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,123 @@
+package response
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type SignatureSuite struct{}
+
+func (s *SignatureSuite) TestSignatureBase(t *testing.T) {
+	header := http.Header{
+		"Content-Type": []string{"application/json"},
+		"X-Multi":      []string{" a ", "b"},
+	}
+
+	base, err := signatureBase(
+		http.StatusOK,
+		headerValues(header),
+		[]string{"@status", "Content-Type", "x-multi"},
+		`("@status" "content-type" "x-multi");created=1618884473;keyid="test-key"`,
+	)
+
+	Expect(err).To(BeNil())
+	Expect(string(base)).To(Equal(`"@status": 200
+"content-type": application/json
+"x-multi": a, b
+"@signature-params": ("@status" "content-type" "x-multi");created=1618884473;keyid="test-key"`))
+}
+
+func (s *SignatureSuite) TestSignHMAC(t *testing.T) {
+	var (
+		key  = NewHMACKey("test-key", []byte("secret"))
+		now  = time.Unix(1618884473, 0)
+		resp = Digest(JSON(map[string]int{"foo": 1}))
+	)
+
+	resp.SetStatusCode(http.StatusCreated)
+	Expect(Sign(resp, key, WithSignatureTime(now))).To(BeNil())
+	Expect(resp.Header("Signature-Input")).To(Equal(
+		`sig1=("@status" "content-type" "content-digest");created=1618884473;keyid="test-key";alg="hmac-sha256"`,
+	))
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(VerifySignature(http.StatusCreated, headers, key)).To(BeNil())
+	Expect(VerifySignature(http.StatusOK, headers, key)).To(Equal(ErrInvalidSignature))
+
+	headers.Set("Content-Type", "text/plain")
+	Expect(VerifySignature(http.StatusCreated, headers, key)).To(Equal(ErrInvalidSignature))
+}
+
+func (s *SignatureSuite) TestSignEd25519(t *testing.T) {
+	var (
+		public, private, _ = ed25519.GenerateKey(bytes.NewReader(make([]byte, 64)))
+		resp               = Respond([]byte("content"))
+	)
+
+	resp.SetHeader("X-Custom", "value")
+	err := Sign(
+		resp,
+		NewEd25519SigningKey("ed-key", private),
+		WithSignatureLabel("partner"),
+		WithCoveredComponents("@status", "x-custom"),
+	)
+
+	Expect(err).To(BeNil())
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(VerifySignature(http.StatusOK, headers, NewEd25519VerifyingKey("ed-key", public))).To(BeNil())
+	Expect(VerifySignature(http.StatusOK, headers, NewEd25519VerifyingKey("ed-key", public), WithSignatureLabel("partner"))).To(BeNil())
+	Expect(VerifySignature(http.StatusOK, headers, NewEd25519VerifyingKey("ed-key", public), WithSignatureLabel("sig1"))).To(MatchError("missing signature input"))
+	Expect(VerifySignature(http.StatusOK, headers, NewEd25519VerifyingKey("other-key", public))).NotTo(BeNil())
+	Expect(VerifySignature(http.StatusOK, headers, NewHMACKey("ed-key", nil))).NotTo(BeNil())
+}
+
+func (s *SignatureSuite) TestSignMultiple(t *testing.T) {
+	var (
+		key1 = NewHMACKey("key1", []byte("secret1"))
+		key2 = NewHMACKey("key2", []byte("secret2"))
+		resp = Empty(http.StatusNoContent)
+	)
+
+	Expect(Sign(resp, key1, WithSignatureLabel("a"))).To(BeNil())
+	Expect(Sign(resp, key2, WithSignatureLabel("b"))).To(BeNil())
+
+	headers, _, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(VerifySignature(http.StatusNoContent, headers, key1, WithSignatureLabel("a"))).To(BeNil())
+	Expect(VerifySignature(http.StatusNoContent, headers, key2, WithSignatureLabel("b"))).To(BeNil())
+	Expect(VerifySignature(http.StatusNoContent, headers, key2, WithSignatureLabel("a"))).NotTo(BeNil())
+}
+
+func (s *SignatureSuite) TestSignExpiration(t *testing.T) {
+	var (
+		key  = NewHMACKey("test-key", []byte("secret"))
+		now  = time.Now()
+		resp = Respond([]byte("content"))
+	)
+
+	Expect(Sign(resp, key, WithSignatureTime(now), WithSignatureExpiration(time.Minute))).To(BeNil())
+	headers, _, _ := Serialize(resp)
+
+	Expect(VerifySignature(http.StatusOK, headers, key, WithSignatureTime(now.Add(time.Second)))).To(BeNil())
+	Expect(VerifySignature(http.StatusOK, headers, key, WithSignatureTime(now.Add(time.Hour)))).To(MatchError("signature expired"))
+}
+
+func (s *SignatureSuite) TestSignMissingComponent(t *testing.T) {
+	var (
+		key  = NewHMACKey("test-key", []byte("secret"))
+		resp = Digest(Stream(ioutil.NopCloser(bytes.NewReader([]byte("content")))))
+	)
+
+	Expect(Sign(resp, key, WithCoveredComponents("@status", "content-digest"))).To(MatchError("covered component content-digest is not present"))
+	Expect(Sign(resp, key, WithCoveredComponents("@method"))).To(MatchError("unsupported derived component @method"))
+	Expect(resp.Header("Signature")).To(BeEmpty())
+}
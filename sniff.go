@@ -0,0 +1,174 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+type (
+	// Signature recognizes content of a particular type by inspecting
+	// (a prefix of) the response body.
+	Signature struct {
+		ContentType string
+		Match       func(data []byte) bool
+	}
+
+	sniffConfig struct {
+		signatures []Signature
+	}
+
+	// SniffConfigFunc is a function used to configure the Sniff function.
+	SniffConfigFunc func(*sniffConfig)
+)
+
+// sniffLen is the number of bytes inspected to determine the content type
+// of a response body.
+const sniffLen = 512
+
+// DefaultSignatures are the signatures consulted by Sniff before falling
+// back to the algorithm of http.DetectContentType.
+var DefaultSignatures = []Signature{
+	{"application/json", isJSON},
+	{"image/svg+xml", isSVG},
+	{"image/webp", isWebP},
+	{"application/vnd.apache.parquet", isParquet},
+}
+
+// WithSignatures registers additional signatures consulted by Sniff. These
+// signatures take precedence over the default signatures.
+func WithSignatures(signatures ...Signature) SniffConfigFunc {
+	return func(c *sniffConfig) { c.signatures = append(c.signatures, signatures...) }
+}
+
+// Sniff sets the Content-Type header of the response based on the first
+// 512 bytes of its body. The body of a stream is peeked from the underlying
+// reader immediately before the headers are written, so the header is
+// visible to decorators but not to code which runs before WriteTo. As the
+// headers are not written until 512 bytes have been read or the stream ends,
+// a slow stream delays the headers. A Content-Type header which has already
+// been set is never overwritten.
+func Sniff(resp Response, configs ...SniffConfigFunc) Response {
+	r, ok := resp.(*response)
+	if !ok {
+		return resp
+	}
+
+	config := &sniffConfig{}
+	for _, f := range configs {
+		f(config)
+	}
+
+	signatures := append(config.signatures, DefaultSignatures...)
+
+	if r.body != nil {
+		setContentType(r, r.body, signatures)
+	} else if r.peek != nil {
+		r.beforeHeader = append(r.beforeHeader, func() {
+			setContentType(r, r.peek(sniffLen), signatures)
+		})
+	}
+
+	return r
+}
+
+// setContentType sets the Content-Type header of the response to the type
+// detected from the given data. Empty bodies and responses with an explicit
+// content type are not modified.
+func setContentType(r *response, data []byte, signatures []Signature) {
+	if len(data) == 0 || r.Header("Content-Type") != "" {
+		return
+	}
+
+	r.SetHeader("Content-Type", detectContentType(data, signatures))
+}
+
+// detectContentType returns the content type of the first matching
+// signature, or the content type detected by http.DetectContentType.
+func detectContentType(data []byte, signatures []Signature) string {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+
+	for _, signature := range signatures {
+		if signature.Match(data) {
+			return signature.ContentType
+		}
+	}
+
+	return http.DetectContentType(data)
+}
+
+// trimLeft removes a leading byte order mark and whitespace.
+func trimLeft(data []byte) []byte {
+	return bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+}
+
+// isJSON returns true if the data is a JSON object or array. A value
+// truncated by the sniffing limit is accepted if its prefix is valid.
+func isJSON(data []byte) bool {
+	data = trimLeft(data)
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	for {
+		if _, err := decoder.Token(); err != nil {
+			return err == io.EOF || err == io.ErrUnexpectedEOF
+		}
+	}
+}
+
+// isSVG returns true if the data is an XML document with an svg root
+// element. An XML declaration, processing instructions, comments, and a
+// document type declaration may precede the root element.
+func isSVG(data []byte) bool {
+	for {
+		data = trimLeft(data)
+
+		var end []byte
+		switch {
+		case bytes.HasPrefix(data, []byte("<?")):
+			end = []byte("?>")
+		case bytes.HasPrefix(data, []byte("<!--")):
+			end = []byte("-->")
+		case bytes.HasPrefix(data, []byte("<!")):
+			end = []byte(">")
+			if i := bytes.IndexAny(data, "[>"); i >= 0 && data[i] == '[' {
+				// Internal subset of the document type declaration
+				end = []byte("]>")
+			}
+		default:
+			return isSVGElement(data)
+		}
+
+		i := bytes.Index(data, end)
+		if i < 0 {
+			return false
+		}
+
+		data = data[i+len(end):]
+	}
+}
+
+// isSVGElement returns true if the data begins with an svg start tag.
+func isSVGElement(data []byte) bool {
+	if len(data) < 4 || !bytes.EqualFold(data[:4], []byte("<svg")) {
+		return false
+	}
+
+	return len(data) == 4 || bytes.IndexByte([]byte(" \t\r\n/>"), data[4]) >= 0
+}
+
+// isWebP returns true if the data is a RIFF container holding a WebP image.
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP"))
+}
+
+// isParquet returns true if the data begins with the Parquet magic number.
+func isParquet(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PAR1"))
+}
@@ -0,0 +1,113 @@
+package response
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type SniffSuite struct{}
+
+func (s *SniffSuite) TestDetectContentType(t *testing.T) {
+	for data, expected := range map[string]string{
+		`{"name": "test", "values": [1, 2, 3]}`: "application/json",
+		"\xef\xbb\xbf  [1, 2, 3]":               "application/json",
+		`{"truncated": "valu`:                   "application/json",
+		`[foo]`:                                 "text/plain; charset=utf-8",
+		`{"a" 1}`:                               "text/plain; charset=utf-8",
+		`<svg xmlns="http://www.w3.org/2000/svg"></svg>`:                                "image/svg+xml",
+		`<?xml version="1.0"?><!-- icon --><SVG></SVG>`:                                 "image/svg+xml",
+		`<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "svg11.dtd"><svg>`:              "image/svg+xml",
+		`<!DOCTYPE svg [<!ENTITY ns "http://www.w3.org/2000/svg">]><svg xmlns="&ns;"/>`: "image/svg+xml",
+		`<!doctype html><html><body><svg viewBox="0 0 1 1"></svg></body></html>`:        "text/html; charset=utf-8",
+		`<!-- <svg> --><html></html>`:                                                   "text/html; charset=utf-8",
+		`<svgx></svgx>`:                                                                 "text/plain; charset=utf-8",
+		"RIFF\x00\x00\x00\x00WEBPVP8 ":                                                  "image/webp",
+		"PAR1\x15\x04":                                                                  "application/vnd.apache.parquet",
+		"<!DOCTYPE html><html></html>":                                                  "text/html; charset=utf-8",
+		"\x89PNG\x0d\x0a\x1a\x0a":                                                       "image/png",
+		"plain old text":                                                                "text/plain; charset=utf-8",
+		"\x00\x01\x02":                                                                  "application/octet-stream",
+	} {
+		Expect(detectContentType([]byte(data), DefaultSignatures)).To(Equal(expected), data)
+	}
+}
+
+func (s *SniffSuite) TestRespond(t *testing.T) {
+	resp := Sniff(Respond([]byte(`{"name": "test"}`)))
+	Expect(resp.Header("Content-Type")).To(Equal("application/json"))
+}
+
+func (s *SniffSuite) TestRespondEmpty(t *testing.T) {
+	resp := Sniff(Respond(nil))
+	Expect(resp.Header("Content-Type")).To(BeEmpty())
+}
+
+func (s *SniffSuite) TestExplicitContentType(t *testing.T) {
+	resp := Sniff(Respond([]byte(`{"name": "test"}`)).SetHeader("Content-Type", "text/plain"))
+	Expect(resp.Header("Content-Type")).To(Equal("text/plain"))
+}
+
+func (s *SniffSuite) TestCustomSignatures(t *testing.T) {
+	resp := Sniff(Respond([]byte("%PDF-1.7")), WithSignatures(Signature{
+		ContentType: "application/x-custom",
+		Match:       func(data []byte) bool { return bytes.HasPrefix(data, []byte("%PDF")) },
+	}))
+
+	Expect(resp.Header("Content-Type")).To(Equal("application/x-custom"))
+}
+
+func (s *SniffSuite) TestStream(t *testing.T) {
+	data := `<svg xmlns="http://www.w3.org/2000/svg">` + strings.Repeat(" ", 64*1024) + `</svg>`
+	resp := Sniff(Stream(ioutil.NopCloser(bytes.NewReader([]byte(data)))))
+
+	contentType := ""
+	resp.DecorateWriter(func(w io.Writer) io.Writer {
+		contentType = w.(http.ResponseWriter).Header().Get("Content-Type")
+		return w
+	})
+
+	Expect(resp.Header("Content-Type")).To(BeEmpty())
+
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+	Expect(w.Header().Get("Content-Type")).To(Equal("image/svg+xml"))
+	Expect(w.Body.String()).To(Equal(data))
+	Expect(contentType).To(Equal("image/svg+xml"))
+}
+
+func (s *SniffSuite) TestStreamShort(t *testing.T) {
+	headers, body, err := Serialize(Sniff(Sniff(Stream(ioutil.NopCloser(bytes.NewReader([]byte("[1, 2, 3]")))))))
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Type")).To(Equal("application/json"))
+	Expect(body).To(Equal([]byte("[1, 2, 3]")))
+}
+
+func (s *SniffSuite) TestStreamError(t *testing.T) {
+	reader := &errorReader{err: errors.New("utoh")}
+	errs := make(chan error, 1)
+
+	resp := Sniff(Stream(ioutil.NopCloser(reader))).AddCallback(func(err error) {
+		errs <- err
+	})
+
+	w := httptest.NewRecorder()
+	resp.WriteTo(w)
+	Expect(w.Header().Get("Content-Type")).To(BeEmpty())
+	Expect(<-errs).To(MatchError("utoh"))
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
@@ -1,6 +1,7 @@
 package response
 
 import (
+	"bufio"
 	"io"
 	"net/http"
 )
@@ -42,7 +43,9 @@ func Stream(rc io.ReadCloser, configs ...StreamConfigFunc) Response {
 		f(config)
 	}
 
-	return newResponse(func(w io.Writer) error {
+	var reader io.Reader = rc
+
+	resp := newResponse(func(w io.Writer) error {
 		defer rc.Close()
 
 		if config.progress != nil {
@@ -52,7 +55,7 @@ func Stream(rc io.ReadCloser, configs ...StreamConfigFunc) Response {
 		buffer := make([]byte, 32*1024)
 
 		for !isClosed(w) {
-			n, err := moveChunk(rc, w, buffer)
+			n, err := moveChunk(reader, w, buffer)
 			if err != nil {
 				if err == io.EOF {
 					break
@@ -72,6 +75,29 @@ func Stream(rc io.ReadCloser, configs ...StreamConfigFunc) Response {
 
 		return nil
 	})
+
+	resp.peek = func(n int) []byte {
+		br, ok := reader.(*bufio.Reader)
+		if !ok || br.Size() < n {
+			br = bufio.NewReaderSize(reader, n)
+			reader = br
+		}
+
+		// A read error is retained by the buffered reader and is
+		// surfaced again once the body is written.
+		data, _ := br.Peek(n)
+		return data
+	}
+
+	resp.release = func() {
+		rc.Close()
+
+		if config.progress != nil {
+			close(config.progress)
+		}
+	}
+
+	return resp
 }
 
 // isClosed returns true if the given writer is a CloseNotifier
@@ -7,14 +7,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"testing"
 
-	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
 )
 
 type StreamSuite struct{}
 
-func (s *StreamSuite) TestStream(t sweet.T) {
+func (s *StreamSuite) TestStream(t *testing.T) {
 	var (
 		data   = makeData()
 		closer = &closer{bytes.NewReader(data), false}
@@ -28,7 +28,7 @@ func (s *StreamSuite) TestStream(t sweet.T) {
 	Expect(closer.closed).To(BeTrue())
 }
 
-func (s *StreamSuite) TestStreamDisconnect(t sweet.T) {
+func (s *StreamSuite) TestStreamDisconnect(t *testing.T) {
 	var (
 		data       = makeData()
 		closeChan  = make(chan bool)
@@ -61,7 +61,7 @@ func (s *StreamSuite) TestStreamDisconnect(t sweet.T) {
 	Expect(body).To(Equal(data[:len(body)]))
 }
 
-func (s *StreamSuite) TestStreamWriteError(t sweet.T) {
+func (s *StreamSuite) TestStreamWriteError(t *testing.T) {
 	var (
 		errors      = make(chan error, 1)
 		expectedErr = fmt.Errorf("utoh")
@@ -79,7 +79,7 @@ func (s *StreamSuite) TestStreamWriteError(t sweet.T) {
 	Consistently(errors).ShouldNot(Receive())
 }
 
-func (s *StreamSuite) TestStreamFlush(t sweet.T) {
+func (s *StreamSuite) TestStreamFlush(t *testing.T) {
 	var (
 		data      = makeData()
 		closeChan = make(chan bool)
@@ -108,7 +108,7 @@ func (s *StreamSuite) TestStreamFlush(t sweet.T) {
 	Eventually(flushCh).Should(BeClosed())
 }
 
-func (s *StreamSuite) TestStreamProgress(t sweet.T) {
+func (s *StreamSuite) TestStreamProgress(t *testing.T) {
 	var (
 		data       = makeData()
 		progressCh = make(chan int, 10)
@@ -177,12 +177,14 @@ type failingResponseWriter struct {
 	numWrites int
 	maxWrites int
 	err       error
+	header    http.Header
 }
 
 func NewFailingResponseWriter(maxWrites int, err error) *failingResponseWriter {
 	return &failingResponseWriter{
 		maxWrites: maxWrites,
 		err:       err,
+		header:    http.Header{},
 	}
 }
 
@@ -190,7 +192,7 @@ func (r *failingResponseWriter) WriteHeader(int) {
 }
 
 func (r *failingResponseWriter) Header() http.Header {
-	return http.Header{}
+	return r.header
 }
 
 func (r *failingResponseWriter) Write(b []byte) (int, error) {
@@ -0,0 +1,123 @@
+package response
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// dictMember is a single member of a structured field dictionary (see
+// RFC 8941). The value is kept in its raw serialized form, including any
+// parameters which follow the member value.
+type dictMember struct {
+	key   string
+	value string
+}
+
+// parseDictionary splits a structured field dictionary into its members.
+// A member without an explicit value receives the boolean true value "?1".
+func parseDictionary(s string) []dictMember {
+	members := []dictMember{}
+	for _, raw := range splitTopLevel(s, ',') {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		key, value := raw, "?1"
+		if i := strings.IndexAny(raw, "=;"); i >= 0 {
+			key = raw[:i]
+
+			if raw[i] == '=' {
+				value = raw[i+1:]
+			} else {
+				value = "?1" + raw[i:]
+			}
+		}
+
+		members = append(members, dictMember{
+			key:   strings.TrimSpace(key),
+			value: strings.TrimSpace(value),
+		})
+	}
+
+	return members
+}
+
+// splitTopLevel splits the string on the given separator, ignoring any
+// separator which occurs within a string, byte sequence, or inner list.
+func splitTopLevel(s string, sep byte) []string {
+	var (
+		parts   = []string{}
+		start   = 0
+		depth   = 0
+		quoted  = false
+		escaped = false
+		binary  = false
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case escaped:
+			escaped = false
+		case quoted:
+			if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				quoted = false
+			}
+		case binary:
+			if c == ':' {
+				binary = false
+			}
+		case c == '"':
+			quoted = true
+		case c == ':':
+			binary = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// formatByteSequence serializes the given bytes as a structured field
+// byte sequence.
+func formatByteSequence(p []byte) string {
+	return ":" + base64.StdEncoding.EncodeToString(p) + ":"
+}
+
+// parseByteSequence deserializes a structured field byte sequence.
+func parseByteSequence(s string) ([]byte, bool) {
+	if len(s) < 2 || s[0] != ':' || s[len(s)-1] != ':' {
+		return nil, false
+	}
+
+	p, err := base64.StdEncoding.DecodeString(s[1 : len(s)-1])
+	if err != nil {
+		return nil, false
+	}
+
+	return p, true
+}
+
+// formatString serializes the given value as a structured field string.
+func formatString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// parseString deserializes a structured field string.
+func parseString(s string) (string, bool) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", false
+	}
+
+	return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(s[1 : len(s)-1]), true
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type StructuredSuite struct{}
+
+func (s *StructuredSuite) TestParseDictionary(t *testing.T) {
+	Expect(parseDictionary(`a=1, b, c=("x" "y,z");p="q,r", d=:YWJj:;x`)).To(Equal([]dictMember{
+		{key: "a", value: "1"},
+		{key: "b", value: "?1"},
+		{key: "c", value: `("x" "y,z");p="q,r"`},
+		{key: "d", value: ":YWJj:;x"},
+	}))
+
+	Expect(parseDictionary(`a;p=1`)).To(Equal([]dictMember{{key: "a", value: "?1;p=1"}}))
+	Expect(parseDictionary(``)).To(BeEmpty())
+}
+
+func (s *StructuredSuite) TestByteSequence(t *testing.T) {
+	Expect(formatByteSequence([]byte("abc"))).To(Equal(":YWJj:"))
+
+	p, ok := parseByteSequence(":YWJj:")
+	Expect(ok).To(BeTrue())
+	Expect(p).To(Equal([]byte("abc")))
+
+	_, ok = parseByteSequence("YWJj")
+	Expect(ok).To(BeFalse())
+	_, ok = parseByteSequence(":!!:")
+	Expect(ok).To(BeFalse())
+}
+
+func (s *StructuredSuite) TestString(t *testing.T) {
+	Expect(formatString(`a "b" \c`)).To(Equal(`"a \"b\" \\c"`))
+
+	v, ok := parseString(`"a \"b\" \\c"`)
+	Expect(ok).To(BeTrue())
+	Expect(v).To(Equal(`a "b" \c`))
+
+	_, ok = parseString(`abc`)
+	Expect(ok).To(BeFalse())
+}
@@ -0,0 +1,264 @@
+package response
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+type (
+	// Renderer renders html/template pages from a file system into
+	// responses. Templates are named by their path relative to the root
+	// of the file system without the extension (such as users/show).
+	// Templates within the layouts directory wrap pages, and templates
+	// within the partials directory are available to all pages and layouts.
+	Renderer struct {
+		fsys   fs.FS
+		config *rendererConfig
+		mutex  sync.Mutex
+		base   *template.Template
+		pages  map[string]*template.Template
+	}
+
+	rendererConfig struct {
+		layoutsDir    string
+		partialsDir   string
+		extension     string
+		defaultLayout string
+		funcs         template.FuncMap
+		hotReload     bool
+	}
+
+	// RendererConfigFunc is a function used to configure a Renderer.
+	RendererConfigFunc func(*rendererConfig)
+
+	renderConfig struct {
+		layout     string
+		earlyFlush bool
+	}
+
+	// RenderConfigFunc is a function used to configure a single call to
+	// the Render method of a Renderer.
+	RenderConfigFunc func(*renderConfig)
+)
+
+// WithLayoutsDir sets the directory which contains layouts. The default
+// directory is layouts.
+func WithLayoutsDir(dir string) RendererConfigFunc {
+	return func(c *rendererConfig) { c.layoutsDir = dir }
+}
+
+// WithPartialsDir sets the directory which contains partials. The default
+// directory is partials.
+func WithPartialsDir(dir string) RendererConfigFunc {
+	return func(c *rendererConfig) { c.partialsDir = dir }
+}
+
+// WithTemplateExtension sets the extension of template files. The default
+// extension is .html.
+func WithTemplateExtension(extension string) RendererConfigFunc {
+	return func(c *rendererConfig) { c.extension = extension }
+}
+
+// WithDefaultLayout sets the name of the layout (relative to the layouts
+// directory) which wraps pages by default. The default layout is base.
+func WithDefaultLayout(layout string) RendererConfigFunc {
+	return func(c *rendererConfig) { c.defaultLayout = layout }
+}
+
+// WithTemplateFuncs adds functions available to all templates.
+func WithTemplateFuncs(funcs template.FuncMap) RendererConfigFunc {
+	return func(c *rendererConfig) {
+		for name, f := range funcs {
+			c.funcs[name] = f
+		}
+	}
+}
+
+// WithHotReload instructs the renderer to re-read templates on every render.
+// This is meant for development so that changes to templates are visible
+// without restarting the server.
+func WithHotReload() RendererConfigFunc {
+	return func(c *rendererConfig) { c.hotReload = true }
+}
+
+// WithLayout sets the layout which wraps the rendered page. An empty name
+// renders the page without a layout.
+func WithLayout(layout string) RenderConfigFunc {
+	return func(c *renderConfig) { c.layout = layout }
+}
+
+// WithEarlyFlush instructs Render to stream the page to the client and to
+// flush the content written so far wherever a template calls the flush
+// function. A layout calls flush after the closing head tag so that clients
+// can start fetching assets while the rest of the page renders. The response
+// status is sent before rendering begins, so errors which occur during
+// rendering are only reported to callbacks.
+func WithEarlyFlush() RenderConfigFunc {
+	return func(c *renderConfig) { c.earlyFlush = true }
+}
+
+// NewRenderer creates a renderer which reads templates from the given file
+// system (such as an embed.FS). An error is returned if the layouts or
+// partials fail to parse.
+func NewRenderer(fsys fs.FS, configs ...RendererConfigFunc) (*Renderer, error) {
+	config := &rendererConfig{
+		layoutsDir:    "layouts",
+		partialsDir:   "partials",
+		extension:     ".html",
+		defaultLayout: "base",
+		funcs: template.FuncMap{
+			"flush": func() string { return "" },
+		},
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	r := &Renderer{fsys: fsys, config: config}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewDirRenderer creates a renderer which reads templates from the given
+// directory.
+func NewDirRenderer(dir string, configs ...RendererConfigFunc) (*Renderer, error) {
+	return NewRenderer(os.DirFS(dir), configs...)
+}
+
+// Render creates a response containing the named page wrapped in the
+// default layout. Errors which occur while rendering produce a response
+// with status 500 whose body reports the error to callbacks.
+func (r *Renderer) Render(name string, data interface{}, configs ...RenderConfigFunc) Response {
+	config := &renderConfig{layout: r.config.defaultLayout}
+	for _, f := range configs {
+		f(config)
+	}
+
+	t, err := r.page(name)
+	if err != nil {
+		return failure(err)
+	}
+
+	entry := name
+	if config.layout != "" {
+		entry = path.Join(r.config.layoutsDir, config.layout)
+
+		if t.Lookup(entry) == nil {
+			return failure(fmt.Errorf("layout %q not found", config.layout))
+		}
+	}
+
+	if !config.earlyFlush {
+		buffer := &bytes.Buffer{}
+		if err := t.ExecuteTemplate(buffer, entry, data); err != nil {
+			return failure(err)
+		}
+
+		resp := Respond(buffer.Bytes())
+		resp.SetHeader("Content-Type", "text/html; charset=utf-8")
+		return resp
+	}
+
+	resp := newResponse(func(w io.Writer) error {
+		flush := func() string {
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+
+			return ""
+		}
+
+		return t.Funcs(template.FuncMap{"flush": flush}).ExecuteTemplate(w, entry, data)
+	})
+
+	resp.SetHeader("Content-Type", "text/html; charset=utf-8")
+	return resp
+}
+
+// page returns a copy of the template set containing the named page along
+// with all layouts and partials. Cached sets are never executed directly so
+// that they can be cloned.
+func (r *Renderer) page(name string) (*template.Template, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.config.hotReload {
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	if t, ok := r.pages[name]; ok {
+		return t.Clone()
+	}
+
+	if !fs.ValidPath(name) || r.isShared(name) {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+
+	content, err := fs.ReadFile(r.fsys, name+r.config.extension)
+	if err != nil {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+
+	t, err := template.Must(r.base.Clone()).New(name).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	r.pages[name] = t
+	return t.Clone()
+}
+
+// load parses all layouts and partials and clears the page cache.
+func (r *Renderer) load() error {
+	base := template.New("").Funcs(r.config.funcs)
+
+	for _, dir := range []string{r.config.layoutsDir, r.config.partialsDir} {
+		err := fs.WalkDir(r.fsys, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(p, r.config.extension) {
+				return err
+			}
+
+			content, err := fs.ReadFile(r.fsys, p)
+			if err != nil {
+				return err
+			}
+
+			_, err = base.New(strings.TrimSuffix(p, r.config.extension)).Parse(string(content))
+			return err
+		})
+
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	r.base = base
+	r.pages = map[string]*template.Template{}
+	return nil
+}
+
+// isShared returns true if the named template is a layout or partial.
+func (r *Renderer) isShared(name string) bool {
+	for _, dir := range []string{r.config.layoutsDir, r.config.partialsDir} {
+		if strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,143 @@
+package response
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/onsi/gomega"
+)
+
+type TemplateSuite struct{}
+
+func (s *TemplateSuite) TestRender(t *testing.T) {
+	renderer, err := NewRenderer(testTemplates())
+	Expect(err).To(BeNil())
+
+	resp := renderer.Render("users/show", map[string]string{"Name": "<bob>"})
+	Expect(resp.StatusCode()).To(Equal(http.StatusOK))
+
+	headers, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(headers.Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+	Expect(headers.Get("Content-Length")).To(Equal(fmt.Sprintf("%d", len(body))))
+	Expect(string(body)).To(Equal("<html><head><title>User</title></head><body><nav>NAV</nav><p>&lt;bob&gt;</p></body></html>"))
+}
+
+func (s *TemplateSuite) TestRenderLayouts(t *testing.T) {
+	renderer, err := NewRenderer(testTemplates(), WithDefaultLayout("bare"))
+	Expect(err).To(BeNil())
+
+	_, body, _ := Serialize(renderer.Render("users/show", map[string]string{"Name": "bob"}))
+	Expect(string(body)).To(Equal("[<p>bob</p>]"))
+
+	_, body, _ = Serialize(renderer.Render("users/show", map[string]string{"Name": "bob"}, WithLayout("")))
+	Expect(string(body)).To(Equal("<p>bob</p>"))
+}
+
+func (s *TemplateSuite) TestRenderFuncs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.tmpl": {Data: []byte(`{{shout .}}`)},
+	}
+
+	renderer, err := NewRenderer(
+		fsys,
+		WithTemplateExtension(".tmpl"),
+		WithTemplateFuncs(template.FuncMap{"shout": func(s string) string { return s + "!" }}),
+	)
+
+	Expect(err).To(BeNil())
+	_, body, _ := Serialize(renderer.Render("index", "hello", WithLayout("")))
+	Expect(string(body)).To(Equal("hello!"))
+}
+
+func (s *TemplateSuite) TestRenderErrors(t *testing.T) {
+	renderer, err := NewRenderer(testTemplates())
+	Expect(err).To(BeNil())
+
+	for _, resp := range []Response{
+		renderer.Render("missing", nil),
+		renderer.Render("../users/show", nil),
+		renderer.Render("partials/nav", nil),
+		renderer.Render("users/show", nil, WithLayout("missing")),
+		renderer.Render("broken", nil),
+	} {
+		Expect(resp.StatusCode()).To(Equal(http.StatusInternalServerError))
+		_, body, err := Serialize(resp)
+		Expect(err).NotTo(BeNil())
+		Expect(body).To(BeEmpty())
+	}
+
+	_, err = NewRenderer(fstest.MapFS{"layouts/base.html": {Data: []byte(`{{`)}})
+	Expect(err).NotTo(BeNil())
+}
+
+func (s *TemplateSuite) TestHotReload(t *testing.T) {
+	var (
+		fsys = fstest.MapFS{"index.html": {Data: []byte(`v1`)}}
+	)
+
+	static, err := NewRenderer(fsys)
+	Expect(err).To(BeNil())
+	reloading, err := NewRenderer(fsys, WithHotReload())
+	Expect(err).To(BeNil())
+
+	_, body, _ := Serialize(static.Render("index", nil, WithLayout("")))
+	Expect(string(body)).To(Equal("v1"))
+
+	fsys["index.html"] = &fstest.MapFile{Data: []byte(`v2`)}
+
+	_, body, _ = Serialize(static.Render("index", nil, WithLayout("")))
+	Expect(string(body)).To(Equal("v1"))
+	_, body, _ = Serialize(reloading.Render("index", nil, WithLayout("")))
+	Expect(string(body)).To(Equal("v2"))
+}
+
+func (s *TemplateSuite) TestEarlyFlush(t *testing.T) {
+	renderer, err := NewRenderer(testTemplates())
+	Expect(err).To(BeNil())
+
+	resp := renderer.Render("users/show", map[string]string{"Name": "bob"}, WithEarlyFlush())
+	Expect(resp.Header("Content-Type")).To(Equal("text/html; charset=utf-8"))
+	Expect(resp.Header("Content-Length")).To(BeEmpty())
+
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	resp.WriteTo(w)
+
+	Expect(w.flushes).To(Equal([]string{"<html><head><title>User</title></head>"}))
+	Expect(w.Body.String()).To(HaveSuffix("<p>bob</p></body></html>"))
+}
+
+func (s *TemplateSuite) TestEarlyFlushError(t *testing.T) {
+	renderer, err := NewRenderer(testTemplates())
+	Expect(err).To(BeNil())
+
+	_, body, err := Serialize(renderer.Render("broken", nil, WithEarlyFlush()))
+	Expect(err).NotTo(BeNil())
+	Expect(string(body)).To(HavePrefix("<html><head>"))
+}
+
+//
+//
+
+func testTemplates() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<html><head><title>{{block "title" .}}Site{{end}}</title></head>{{flush}}<body>{{template "partials/nav"}}{{template "content" .}}</body></html>`)},
+		"layouts/bare.html": {Data: []byte(`[{{template "content" .}}]`)},
+		"partials/nav.html": {Data: []byte(`<nav>NAV</nav>`)},
+		"users/show.html":   {Data: []byte(`{{define "title"}}User{{end}}{{define "content"}}<p>{{.Name}}</p>{{end}}{{template "content" .}}`)},
+		"broken.html":       {Data: []byte(`{{define "content"}}{{index . 5}}{{end}}`)},
+	}
+}
+
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []string
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes = append(r.flushes, r.Body.String())
+}
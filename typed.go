@@ -0,0 +1,217 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+type (
+	typedConfig struct {
+		maxBodySize           int64
+		disallowUnknownFields bool
+		statusCode            int
+	}
+
+	// TypedConfigFunc is a function used to configure the Typed adapter.
+	TypedConfigFunc func(*typedConfig)
+
+	// Validator is implemented by request types which check their own
+	// semantic validity after being decoded. If the returned error is a
+	// *Problem it is sent as-is, otherwise the request is rejected with a
+	// 422 describing the error.
+	Validator interface {
+		Validate() error
+	}
+
+	requestKey struct{}
+)
+
+// WithMaxBodySize sets the maximum size of a request body in bytes. Larger
+// requests are rejected with a 413. The default limit is 1MiB.
+func WithMaxBodySize(maxBodySize int64) TypedConfigFunc {
+	return func(c *typedConfig) { c.maxBodySize = maxBodySize }
+}
+
+// WithDisallowUnknownFields instructs Typed to reject request bodies with
+// fields that do not exist in the input type with a 422. By default, unknown
+// fields are ignored.
+func WithDisallowUnknownFields() TypedConfigFunc {
+	return func(c *typedConfig) { c.disallowUnknownFields = true }
+}
+
+// WithSuccessStatus sets the status code of responses for which the handler
+// returns no error. The default status code is 200. The output value is not
+// sent for a 204.
+func WithSuccessStatus(statusCode int) TypedConfigFunc {
+	return func(c *typedConfig) { c.statusCode = statusCode }
+}
+
+// Typed adapts a function which accepts a decoded request body and returns
+// a value to encode as the response body into a HandlerFunc. The request
+// body, if non-empty, must be JSON and is decoded into a value of type In;
+// an empty body leaves the zero value. Malformed bodies are rejected with a
+// 400, bodies with another media type with a 415, and bodies which cannot
+// be decoded into the input type or which fail validation with a 422. All
+// rejections are sent as problem details (see ProblemJSON). Fields missing
+// from the body are left as zero values; the input type should implement
+// Validator to reject requests which omit fields that are required.
+//
+// The output value is serialized as with the JSON constructor, unless it
+// is itself a Response, in which case it is sent as-is. If the function
+// returns a *Problem, it is sent as the response. Other errors produce a
+// response with status 500 and are reported to callbacks. The request is
+// available to the function via RequestFromContext.
+func Typed[In, Out any](f func(context.Context, In) (Out, error), configs ...TypedConfigFunc) HandlerFunc {
+	config := newTypedConfig(configs)
+
+	return func(r *http.Request) Response {
+		var in In
+		if err := decodeRequest(r, &in, config); err != nil {
+			return errorResponse(err)
+		}
+
+		out, err := f(context.WithValue(r.Context(), requestKey{}, r), in)
+		if err != nil {
+			return errorResponse(err)
+		}
+
+		if resp, ok := any(out).(Response); ok {
+			return resp
+		}
+
+		if config.statusCode == http.StatusNoContent {
+			return Empty(http.StatusNoContent)
+		}
+
+		return JSON(out).SetStatusCode(config.statusCode)
+	}
+}
+
+// newTypedConfig applies the given config functions over defaults.
+func newTypedConfig(configs []TypedConfigFunc) *typedConfig {
+	config := &typedConfig{
+		maxBodySize:           1 << 20,
+		disallowUnknownFields: false,
+		statusCode:            http.StatusOK,
+	}
+
+	for _, f := range configs {
+		f(config)
+	}
+
+	return config
+}
+
+// RequestFromContext returns the request being handled by a function
+// adapted by Typed, or nil.
+func RequestFromContext(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(requestKey{}).(*http.Request)
+	return r
+}
+
+// errorResponse creates a problem response if the error is a *Problem, and
+// otherwise a response which reports the error to callbacks.
+func errorResponse(err error) Response {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return ProblemJSON(problem)
+	}
+
+	return failure(err)
+}
+
+// decodeRequest decodes the JSON body of the request into the target value
+// and validates the result. The returned error is a *Problem for failures
+// attributable to the client.
+func decodeRequest(r *http.Request, target interface{}, config *typedConfig) error {
+	if r.Body != nil {
+		data, err := io.ReadAll(io.LimitReader(r.Body, config.maxBodySize+1))
+		if err != nil {
+			return NewProblem(http.StatusBadRequest, "failed to read request body")
+		}
+
+		if int64(len(data)) > config.maxBodySize {
+			return NewProblem(http.StatusRequestEntityTooLarge, "request body exceeds %d bytes", config.maxBodySize)
+		}
+
+		if len(data) > 0 {
+			if err := decodeJSON(r.Header.Get("Content-Type"), data, target, config); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v, ok := target.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			var problem *Problem
+			if errors.As(err, &problem) {
+				return problem
+			}
+
+			return NewProblem(http.StatusUnprocessableEntity, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// decodeJSON decodes a single JSON value from data into the target value.
+func decodeJSON(contentType string, data []byte, target interface{}, config *typedConfig) error {
+	if !isJSONMediaType(contentType) {
+		return NewProblem(http.StatusUnsupportedMediaType, "request body must be application/json")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if config.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(target); err != nil {
+		var (
+			syntaxErr *json.SyntaxError
+			typeErr   *json.UnmarshalTypeError
+		)
+
+		switch {
+		case errors.As(err, &syntaxErr):
+			return NewProblem(http.StatusBadRequest, "malformed JSON at offset %d", syntaxErr.Offset)
+
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return NewProblem(http.StatusBadRequest, "malformed JSON: unexpected end of input")
+
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			return NewProblem(http.StatusUnprocessableEntity, "field %q must be of type %s", typeErr.Field, typeErr.Type)
+
+		case errors.As(err, &typeErr):
+			return NewProblem(http.StatusUnprocessableEntity, "request body must be of type %s", typeErr.Type)
+
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			return NewProblem(http.StatusUnprocessableEntity, "unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+		}
+
+		return NewProblem(http.StatusBadRequest, "malformed JSON")
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return NewProblem(http.StatusBadRequest, "unexpected data after JSON value")
+	}
+
+	return nil
+}
+
+// isJSONMediaType returns true if the given content type is application/json
+// or uses the +json structured syntax suffix.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,162 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type TypedSuite struct{}
+
+type (
+	typedInput struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	typedOutput struct {
+		Greeting string `json:"greeting"`
+	}
+)
+
+func (i typedInput) Validate() error {
+	if i.Count < 0 {
+		return errors.New("count must not be negative")
+	}
+
+	if i.Name == "forbidden" {
+		return NewProblem(http.StatusForbidden, "name is reserved")
+	}
+
+	return nil
+}
+
+func greet(ctx context.Context, in typedInput) (typedOutput, error) {
+	return typedOutput{Greeting: fmt.Sprintf("hello %s x%d", in.Name, in.Count)}, nil
+}
+
+func (s *TypedSuite) TestTyped(t *testing.T) {
+	resp := Typed(greet)(jsonRequest(`{"name": "bob", "count": 2}`))
+	Expect(resp.StatusCode()).To(Equal(http.StatusOK))
+	Expect(resp.Header("Content-Type")).To(Equal("application/json"))
+
+	_, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(body).To(MatchJSON(`{"greeting": "hello bob x2"}`))
+}
+
+func (s *TypedSuite) TestEmptyBody(t *testing.T) {
+	resp := Typed(greet)(httptest.NewRequest("GET", "/", nil))
+	_, body, err := Serialize(resp)
+	Expect(err).To(BeNil())
+	Expect(body).To(MatchJSON(`{"greeting": "hello  x0"}`))
+}
+
+func (s *TypedSuite) TestDecodeFailures(t *testing.T) {
+	for _, testCase := range []struct {
+		contentType string
+		body        string
+		statusCode  int
+		detail      string
+	}{
+		{"application/json", `{"name": `, http.StatusBadRequest, "malformed JSON: unexpected end of input"},
+		{"application/json", `{"name" "bob"}`, http.StatusBadRequest, "malformed JSON at offset 9"},
+		{"application/json", `{"name": "bob"} {}`, http.StatusBadRequest, "unexpected data after JSON value"},
+		{"application/json", `{"count": "two"}`, http.StatusUnprocessableEntity, `field "count" must be of type int`},
+		{"application/json", `[1, 2]`, http.StatusUnprocessableEntity, "request body must be of type response.typedInput"},
+		{"application/json", `{"count": -1}`, http.StatusUnprocessableEntity, "count must not be negative"},
+		{"application/json", `{"name": "forbidden"}`, http.StatusForbidden, "name is reserved"},
+		{"text/plain", `{"name": "bob"}`, http.StatusUnsupportedMediaType, "request body must be application/json"},
+		{"", `{"name": "bob"}`, http.StatusUnsupportedMediaType, "request body must be application/json"},
+	} {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(testCase.body))
+		r.Header.Set("Content-Type", testCase.contentType)
+
+		resp := Typed(greet)(r)
+		Expect(resp.StatusCode()).To(Equal(testCase.statusCode), testCase.body)
+		Expect(resp.Header("Content-Type")).To(Equal("application/problem+json"))
+
+		_, body, err := Serialize(resp)
+		Expect(err).To(BeNil())
+		Expect(body).To(MatchJSON(fmt.Sprintf(
+			`{"title": %q, "status": %d, "detail": %q}`,
+			http.StatusText(testCase.statusCode),
+			testCase.statusCode,
+			testCase.detail,
+		)))
+	}
+}
+
+func (s *TypedSuite) TestStructuredSuffix(t *testing.T) {
+	r := jsonRequest(`{"name": "bob"}`)
+	r.Header.Set("Content-Type", "application/vnd.example+json; charset=utf-8")
+	Expect(Typed(greet)(r).StatusCode()).To(Equal(http.StatusOK))
+}
+
+func (s *TypedSuite) TestUnknownFields(t *testing.T) {
+	Expect(Typed(greet)(jsonRequest(`{"name": "bob", "extra": true}`)).StatusCode()).To(Equal(http.StatusOK))
+
+	resp := Typed(greet, WithDisallowUnknownFields())(jsonRequest(`{"name": "bob", "extra": true}`))
+	Expect(resp.StatusCode()).To(Equal(http.StatusUnprocessableEntity))
+
+	_, body, _ := Serialize(resp)
+	Expect(body).To(ContainSubstring(`unknown field \"extra\"`))
+}
+
+func (s *TypedSuite) TestMaxBodySize(t *testing.T) {
+	handler := Typed(greet, WithMaxBodySize(16))
+	Expect(handler(jsonRequest(`{"name": "bob"}`)).StatusCode()).To(Equal(http.StatusOK))
+	Expect(handler(jsonRequest(`{"name": "bobby tables"}`)).StatusCode()).To(Equal(http.StatusRequestEntityTooLarge))
+}
+
+func (s *TypedSuite) TestSuccessStatus(t *testing.T) {
+	Expect(Typed(greet, WithSuccessStatus(http.StatusCreated))(jsonRequest(`{}`)).StatusCode()).To(Equal(http.StatusCreated))
+
+	_, body, err := Serialize(Typed(greet, WithSuccessStatus(http.StatusNoContent))(jsonRequest(`{}`)))
+	Expect(err).To(BeNil())
+	Expect(body).To(BeEmpty())
+}
+
+func (s *TypedSuite) TestResponseOutput(t *testing.T) {
+	handler := Typed(func(ctx context.Context, in struct{}) (Response, error) {
+		return Text("path %s", RequestFromContext(ctx).URL.Path), nil
+	})
+
+	_, body, err := Serialize(handler(httptest.NewRequest("GET", "/items", nil)))
+	Expect(err).To(BeNil())
+	Expect(body).To(Equal([]byte("path /items")))
+}
+
+func (s *TypedSuite) TestErrors(t *testing.T) {
+	handler := Typed(func(ctx context.Context, in struct{}) (struct{}, error) {
+		return struct{}{}, fmt.Errorf("lookup failed: %w", NewProblem(http.StatusNotFound, "no such item"))
+	})
+
+	Expect(handler(jsonRequest(`{}`)).StatusCode()).To(Equal(http.StatusNotFound))
+
+	handler = Typed(func(ctx context.Context, in struct{}) (struct{}, error) {
+		return struct{}{}, errors.New("utoh")
+	})
+
+	resp := handler(jsonRequest(`{}`))
+	Expect(resp.StatusCode()).To(Equal(http.StatusInternalServerError))
+
+	_, _, err := Serialize(resp)
+	Expect(err).To(MatchError("utoh"))
+}
+
+func (s *TypedSuite) TestRequestFromContext(t *testing.T) {
+	Expect(RequestFromContext(context.Background())).To(BeNil())
+}
+
+func jsonRequest(body string) *http.Request {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}